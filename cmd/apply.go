@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/odysa/histctl/internal/backup"
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/config"
+	"github.com/odysa/histctl/internal/plan"
+	"github.com/odysa/histctl/internal/process"
+	"github.com/spf13/cobra"
+)
+
+var (
+	applyYes      bool
+	applyNoBackup bool
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply <plan.json>",
+	Short: "Execute a plan written by delete --plan",
+	Long: `Delete exactly the visits listed in a plan file. If a database changed
+since the plan was written, every planned visit must still exist unchanged
+or the browser is refused.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := plan.Read(args[0])
+		if err != nil {
+			return err
+		}
+		cfg, err := config.Load(configFlag)
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		var hadErrors bool
+		for _, pb := range f.Browsers {
+			if err := applyBrowser(ctx, pb, cfg); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %s: %v\n", pb.Name, err)
+				hadErrors = true
+			}
+		}
+
+		if hadErrors {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+func applyBrowser(ctx context.Context, pb plan.Browser, protect browser.Protector) error {
+	if len(pb.Entries) == 0 {
+		fmt.Printf("[%s] nothing planned\n", pb.Name)
+		return nil
+	}
+
+	b, err := browser.Get(pb.Name)
+	if err != nil {
+		return err
+	}
+	dbPath, err := b.DBPath()
+	if err != nil {
+		return err
+	}
+	if dbPath != pb.DBPath {
+		return fmt.Errorf("plan was written for %s, but history is now at %s", pb.DBPath, dbPath)
+	}
+
+	running, err := process.IsRunning(b.ProcessName())
+	if err != nil {
+		return fmt.Errorf("could not check if %s is running: %w", b.Name(), err)
+	}
+	if running {
+		return fmt.Errorf("%s is running — close it first", b.Name())
+	}
+
+	fp, err := plan.FingerprintDB(dbPath)
+	if err != nil {
+		return err
+	}
+	if fp.SHA256 != pb.Fingerprint.SHA256 {
+		current, err := b.List(ctx, browser.ListOptions{})
+		if err != nil {
+			return err
+		}
+		if err := pb.Verify(current); err != nil {
+			return fmt.Errorf("refusing: database changed since the plan was written: %w", err)
+		}
+		fmt.Printf("[%s] database changed since the plan was written; all planned visits verified\n", b.Name())
+	}
+
+	if !applyYes {
+		fmt.Printf("[%s] delete %d planned entries? (y/N): ", b.Name(), len(pb.Entries))
+		var answer string
+		fmt.Scanln(&answer)
+		if answer != "y" && answer != "Y" {
+			fmt.Println("  skipped")
+			return nil
+		}
+	}
+
+	if !applyNoBackup {
+		backupPath, err := backup.Create(dbPath)
+		if err != nil {
+			return fmt.Errorf("backup failed: %w", err)
+		}
+		fmt.Printf("[%s] backed up to %s\n", b.Name(), backupPath)
+	}
+
+	result, err := b.Delete(ctx, browser.DeleteOptions{
+		Entries:           pb.HistoryEntries(),
+		IncludeBookmarked: pb.IncludeBookmarked,
+		Protect:           protect,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("[%s] deleted %d entries\n", b.Name(), result.Deleted)
+	printProtected(os.Stdout, b, result.Protected)
+	return nil
+}
+
+func init() {
+	applyCmd.Flags().BoolVarP(&applyYes, "yes", "y", false, "Skip confirmation prompt")
+	applyCmd.Flags().BoolVar(&applyNoBackup, "no-backup", false, "Skip creating a backup")
+	rootCmd.AddCommand(applyCmd)
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/odysa/histctl/internal/archive"
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	archiveDB          string
+	archiveSearchLimit int
+	archiveSearchJSON  bool
+	archivePages       bool
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Keep a private archive of history that outlives deletes",
+	Long: `Keep a private, long-term record of browser history in histctl's own
+SQLite database, searchable by the words of URLs and titles.
+
+archive sync copies the visits of every installed browser (or --browser)
+into it, skipping visits it already has. With --pages it also indexes the
+text of archived pages still in the browsers' disk caches (Chrome, Edge and
+Firefox), so they can be found by their content. delete --archive archives
+the matched visits before removing them.`,
+}
+
+var archiveSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Add new visits from the browsers to the archive",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		browsers, err := resolveBrowsers()
+		if err != nil {
+			return err
+		}
+		path, err := archivePath(archiveDB)
+		if err != nil {
+			return err
+		}
+		a, err := archive.Open(path)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		ctx := context.Background()
+		for _, b := range browsers {
+			entries, err := b.List(ctx, browser.ListOptions{})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %s: %v\n", b.Name(), err)
+				continue
+			}
+			result, err := a.Add(ctx, entries)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("[%s] archived %d new visits, %d already archived\n", b.Name(), result.Added, result.Skipped)
+		}
+
+		if archivePages {
+			for _, b := range browsers {
+				c, ok := b.(browser.Cacher)
+				if !ok {
+					continue
+				}
+				dir, err := c.CacheDir()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "warning: %s: %v\n", b.Name(), err)
+					continue
+				}
+				result, err := a.IndexCache(ctx, dir)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("[%s] indexed the text of %d pages from %d cache entries\n", b.Name(), result.Pages, result.Entries)
+			}
+		}
+
+		n, err := a.Count(ctx)
+		if err != nil {
+			return err
+		}
+		pages, err := a.PageCount(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d visits and %d pages in %s\n", n, pages, a.Path())
+		return nil
+	},
+}
+
+var archiveSearchCmd = &cobra.Command{
+	Use:   "search <words>...",
+	Short: "Search the archive by words of URLs, titles and page text",
+	Long: `Search the archive for visits whose URL or title, or the page text
+indexed by archive sync --pages, contains every word, best matches first.
+Words match whole words of the text, except the last, which also matches as
+a prefix; "github.com" matches the words github and com in a row.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := archive.SearchOptions{Limit: archiveSearchLimit}
+		if browserFlag != "all" {
+			b, err := browser.Get(browserFlag)
+			if err != nil {
+				return err
+			}
+			opts.Browser = b.Name()
+		}
+		path, err := archivePath(archiveDB)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("no archive at %s; run histctl archive sync first", path)
+		}
+		a, err := archive.Open(path)
+		if err != nil {
+			return err
+		}
+		defer a.Close()
+
+		entries, err := a.Search(context.Background(), strings.Join(args, " "), opts)
+		if err != nil {
+			return err
+		}
+		if archiveSearchJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(entries)
+		}
+		return printEntries(entries)
+	},
+}
+
+// archiveDefault is the value of delete --archive given without a database.
+const archiveDefault = "default"
+
+// archivePath resolves a --db or --archive value; "" and archiveDefault
+// mean archive.DefaultPath.
+func archivePath(path string) (string, error) {
+	if path != "" && path != archiveDefault {
+		return path, nil
+	}
+	path, err := archive.DefaultPath()
+	if err != nil {
+		return "", fmt.Errorf("locate the archive: %w; use --db", err)
+	}
+	return path, nil
+}
+
+func init() {
+	archiveCmd.PersistentFlags().StringVar(&archiveDB, "db", "", "Archive database (default $XDG_DATA_HOME/histctl/archive.db)")
+	archiveSyncCmd.Flags().BoolVar(&archivePages, "pages", false, "Also index the text of archived pages in the browsers' disk caches")
+	archiveSearchCmd.Flags().IntVarP(&archiveSearchLimit, "limit", "n", 50, "Max entries to display")
+	archiveSearchCmd.Flags().BoolVar(&archiveSearchJSON, "json", false, "Output as JSON")
+	archiveCmd.AddCommand(archiveSyncCmd, archiveSearchCmd)
+	rootCmd.AddCommand(archiveCmd)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/odysa/histctl/internal/config"
+	"github.com/odysa/histctl/internal/rules"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanRules    string
+	cleanDryRun   bool
+	cleanYes      bool
+	cleanNoBackup bool
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Delete history according to a rules file",
+	Long: `Evaluate the cleanup rules in rules.toml (or --rules) against every
+browser, print the plan, and apply it after confirmation.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rs, err := rules.Load(cleanRules)
+		if err != nil {
+			return err
+		}
+		cfg, err := config.Load(configFlag)
+		if err != nil {
+			return err
+		}
+		browsers, err := resolveBrowsers()
+		if err != nil {
+			return err
+		}
+		if len(browsers) == 0 {
+			fmt.Fprintln(os.Stderr, "No supported browsers found.")
+			return nil
+		}
+
+		ctx := context.Background()
+		plan := rules.Evaluate(ctx, rs, browsers, cfg, time.Now())
+		printPlan(plan)
+
+		if cleanDryRun {
+			return nil
+		}
+		if plan.Matched() == 0 {
+			fmt.Println("nothing to clean")
+			return nil
+		}
+		if !cleanYes {
+			fmt.Printf("apply plan and delete %d entries? (y/N): ", plan.Matched())
+			var answer string
+			fmt.Scanln(&answer)
+			if answer != "y" && answer != "Y" {
+				fmt.Println("  skipped")
+				return nil
+			}
+		}
+
+		var hadErrors bool
+		for _, out := range rules.Apply(ctx, plan, rules.ApplyOptions{NoBackup: cleanNoBackup}) {
+			if !printOutcome(out) {
+				hadErrors = true
+			}
+		}
+		if hadErrors {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+func printPlan(plan rules.Plan) {
+	var last string
+	for _, a := range plan.Actions {
+		if a.Rule.Name != last {
+			fmt.Printf("rule %s\n", a.Rule)
+			last = a.Rule.Name
+		}
+		if a.Err != nil {
+			fmt.Fprintf(os.Stderr, "  error: %s: %v\n", a.Browser.Name(), a.Err)
+			continue
+		}
+		line := fmt.Sprintf("  [%s] %d entries", a.Browser.Name(), a.Preview.Matched)
+		if n := len(a.Preview.Protected); n > 0 {
+			line += fmt.Sprintf(", %d protected", n)
+		}
+		fmt.Println(line)
+	}
+}
+
+// printOutcome reports one browser's result and whether it succeeded.
+func printOutcome(out rules.Outcome) bool {
+	switch {
+	case out.Err != nil:
+		fmt.Fprintf(os.Stderr, "error: %s: %v\n", out.Browser, out.Err)
+		return false
+	case out.Skipped == "running":
+		fmt.Fprintf(os.Stderr, "error: %s is running — close it first\n", out.Browser)
+		return false
+	}
+	if out.Backup != "" {
+		fmt.Printf("[%s] backed up to %s\n", out.Browser, out.Backup)
+	}
+	fmt.Printf("[%s] deleted %d entries\n", out.Browser, out.Deleted)
+	return true
+}
+
+func init() {
+	cleanCmd.Flags().StringVar(&cleanRules, "rules", "", "Rules file (default $XDG_CONFIG_HOME/histctl/rules.toml)")
+	cleanCmd.Flags().BoolVarP(&cleanDryRun, "dry-run", "d", false, "Print the plan without deleting")
+	cleanCmd.Flags().BoolVarP(&cleanYes, "yes", "y", false, "Skip confirmation prompt")
+	cleanCmd.Flags().BoolVar(&cleanNoBackup, "no-backup", false, "Skip creating backups")
+	rootCmd.AddCommand(cleanCmd)
+}
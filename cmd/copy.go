@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/domain"
+	"github.com/odysa/histctl/internal/query"
+	"github.com/spf13/cobra"
+)
+
+var (
+	copyFrom     string
+	copyTo       string
+	copySince    string
+	copyDomain   string
+	copyFilter   string
+	copyDryRun   bool
+	copyNoBackup bool
+)
+
+var copyCmd = &cobra.Command{
+	Use:   "copy --from <browser> --to <browser> [pattern]",
+	Short: "Copy history visits from one browser into another",
+	Long: `Copy history visits matching a regex pattern, --domain, --since and
+--filter (all of history if none is given) from one browser into another,
+keeping titles and visit times. The source is only read; visits the target
+already has are skipped, so copying again adds only what is new.
+
+The target browser must be closed and its database is backed up first.
+Any browser can be copied from; chrome, edge and firefox can be copied to.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if copyFrom == copyTo {
+			return fmt.Errorf("--from and --to are both %s", copyFrom)
+		}
+		from, err := browser.Get(copyFrom)
+		if err != nil {
+			return err
+		}
+		if _, err := from.DBPath(); err != nil {
+			return fmt.Errorf("%s is not installed or history not found: %w", from.Name(), err)
+		}
+		to, err := browser.Get(copyTo)
+		if err != nil {
+			return err
+		}
+		return copyHistory(from, to, args)
+	},
+}
+
+// copyHistory copies the visits of from matching args and the copy flags
+// into to.
+func copyHistory(from, to browser.Browser, args []string) error {
+	importer, ok := to.(browser.Importer)
+	if !ok {
+		return fmt.Errorf("copying into %s is not supported", to.Name())
+	}
+	dbPath, err := to.DBPath()
+	if err != nil {
+		return fmt.Errorf("%s is not installed or history not found: %w", to.Name(), err)
+	}
+
+	var opts browser.ListOptions
+	if len(args) > 0 {
+		re, err := regexp.Compile("(?i)" + args[0])
+		if err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+		opts.Pattern = re
+	}
+	if copyDomain != "" {
+		if opts.Domain, err = domain.Normalize(copyDomain); err != nil {
+			return err
+		}
+	}
+	if copySince != "" {
+		if opts.Since, err = query.ParseTime(copySince, time.Now()); err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if err := applyFilter(&opts, copyFilter); err != nil {
+		return err
+	}
+
+	entries, err := from.List(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("[%s] %d matching visits\n", from.Name(), len(entries))
+	if len(entries) == 0 {
+		return nil
+	}
+	return importEntries(to, importer, dbPath, entries, copyDryRun, !copyNoBackup)
+}
+
+func init() {
+	copyCmd.Flags().StringVar(&copyFrom, "from", "", "Browser to copy from: safari|chrome|edge|firefox")
+	copyCmd.Flags().StringVar(&copyTo, "to", "", "Browser to copy into: chrome|edge|firefox")
+	copyCmd.Flags().StringVar(&copySince, "since", "", "Only visits since a date (2006-01-02), RFC 3339 time or age such as 30d")
+	copyCmd.Flags().StringVar(&copyDomain, "domain", "", "Only visits on this domain or its subdomains")
+	copyCmd.Flags().StringVar(&copyFilter, "filter", "", "Filter expression such as 'host:github.com AND visits>3'")
+	copyCmd.Flags().BoolVarP(&copyDryRun, "dry-run", "d", false, "Count what would be copied without writing")
+	copyCmd.Flags().BoolVar(&copyNoBackup, "no-backup", false, "Skip backup")
+	copyCmd.MarkFlagRequired("from")
+	copyCmd.MarkFlagRequired("to")
+	rootCmd.AddCommand(copyCmd)
+}
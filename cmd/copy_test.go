@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+	_ "modernc.org/sqlite"
+)
+
+const copyChromeSchema = `
+CREATE TABLE urls (
+	id INTEGER PRIMARY KEY,
+	url TEXT,
+	title TEXT,
+	visit_count INTEGER DEFAULT 0
+);
+CREATE TABLE visits (
+	id INTEGER PRIMARY KEY,
+	url INTEGER REFERENCES urls(id),
+	visit_time INTEGER
+);`
+
+const copyFirefoxSchema = `
+CREATE TABLE moz_origins (
+	id INTEGER PRIMARY KEY,
+	prefix TEXT NOT NULL,
+	host TEXT NOT NULL,
+	frecency INTEGER NOT NULL,
+	UNIQUE (prefix, host)
+);
+CREATE TABLE moz_places (
+	id INTEGER PRIMARY KEY,
+	url LONGVARCHAR,
+	title LONGVARCHAR,
+	rev_host LONGVARCHAR,
+	visit_count INTEGER DEFAULT 0,
+	hidden INTEGER DEFAULT 0 NOT NULL,
+	typed INTEGER DEFAULT 0 NOT NULL,
+	frecency INTEGER DEFAULT -1 NOT NULL,
+	last_visit_date INTEGER,
+	guid TEXT,
+	url_hash INTEGER DEFAULT 0 NOT NULL,
+	origin_id INTEGER REFERENCES moz_origins(id),
+	recalc_frecency INTEGER NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX moz_places_guid_uniqueindex ON moz_places (guid);
+CREATE TABLE moz_historyvisits (
+	id INTEGER PRIMARY KEY,
+	from_visit INTEGER,
+	place_id INTEGER,
+	visit_date INTEGER,
+	visit_type INTEGER,
+	session INTEGER
+);
+CREATE TABLE moz_bookmarks (
+	id INTEGER PRIMARY KEY,
+	type INTEGER,
+	fk INTEGER DEFAULT NULL,
+	title TEXT
+);`
+
+// closedFirefox is a Firefox whose process is never running, so the test
+// does not depend on the browsers of the machine it runs on.
+type closedFirefox struct{ *browser.Firefox }
+
+func (closedFirefox) ProcessName() string { return "" }
+
+func createDB(t *testing.T, schema, seed string, args ...any) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "history.db")
+	db, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("create db: %v", err)
+	}
+	if seed != "" {
+		if _, err := db.Exec(seed, args...); err != nil {
+			t.Fatalf("seed db: %v", err)
+		}
+	}
+	return path
+}
+
+func TestCopyChromeToFirefox(t *testing.T) {
+	// Chrome times are microseconds since 1601.
+	chromeTime := func(unix int64) int64 { return (unix + 11644473600) * 1_000_000 }
+	now := time.Now().Unix()
+	recent, old := chromeTime(now-3600), chromeTime(now-90*24*3600)
+	src := createDB(t, copyChromeSchema, `
+		INSERT INTO urls (id, url, title) VALUES
+			(1, 'https://example.com/', 'Example'),
+			(2, 'https://docs.example.com/a', 'Docs'),
+			(3, 'https://golang.org/', 'Go');
+		INSERT INTO visits (url, visit_time) VALUES
+			(1, ?1), (1, ?2), (1, ?3), (2, ?1), (3, ?1);`,
+		recent, recent+1_000_000, old)
+	dst := createDB(t, copyFirefoxSchema, "")
+
+	copyDomain, copySince, copyDryRun, copyNoBackup = "example.com", "30d", false, true
+	t.Cleanup(func() { copyDomain, copySince, copyNoBackup = "", "", false })
+	from, to := browser.NewChrome(src), closedFirefox{browser.NewFirefox(dst)}
+	for range 2 {
+		if err := copyHistory(from, to, nil); err != nil {
+			t.Fatalf("copyHistory() error: %v", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", "file:"+dst+"?mode=ro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	rows, err := db.Query(`SELECT p.url, p.title, p.visit_count, p.url_hash, COUNT(v.id)
+		FROM moz_places p JOIN moz_historyvisits v ON v.place_id = p.id GROUP BY p.id ORDER BY p.url`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	type page struct {
+		url, title    string
+		count, visits int
+		urlHash       int64
+	}
+	var got []page
+	for rows.Next() {
+		var p page
+		if err := rows.Scan(&p.url, &p.title, &p.count, &p.urlHash, &p.visits); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, p)
+	}
+	// golang.org is off the domain and the 90-day-old visit before --since;
+	// copying twice adds nothing the second time.
+	if len(got) != 2 {
+		t.Fatalf("copied pages = %+v, want docs.example.com and example.com", got)
+	}
+	if p := got[0]; p.url != "https://docs.example.com/a" || p.title != "Docs" || p.count != 1 || p.visits != 1 {
+		t.Errorf("docs page = %+v, want one visit", p)
+	}
+	if p := got[1]; p.url != "https://example.com/" || p.count != 2 || p.visits != 2 || p.urlHash != 47357371248711 {
+		t.Errorf("example.com page = %+v, want 2 visits and Firefox's url_hash", p)
+	}
+}
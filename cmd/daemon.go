@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/config"
+	"github.com/odysa/histctl/internal/daemon"
+	"github.com/odysa/histctl/internal/rules"
+	"github.com/spf13/cobra"
+)
+
+var (
+	daemonRules    string
+	daemonInterval time.Duration
+	daemonPoll     time.Duration
+	daemonNoBackup bool
+	daemonLog      string
+
+	unitInstall bool
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Apply cleanup rules periodically and whenever a browser exits",
+	Long: `Run in the foreground, applying the rules file (see clean) at startup,
+every --interval, and to each browser right after it exits. Running browsers
+are skipped. Each run is logged.
+
+Before deleting, each run replaces a single backup per browser,
+<db>.daemon.bak, instead of adding a timestamped one, so expired history is
+kept in at most one copy. Use --no-backup to keep none.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := daemon.CheckIntervals(daemonInterval, daemonPoll); err != nil {
+			return err
+		}
+		// Fail fast on a broken rules file; later runs reload it.
+		if _, err := rules.Load(daemonRules); err != nil {
+			return err
+		}
+		browsers, err := resolveBrowsers()
+		if err != nil {
+			return err
+		}
+
+		logOut := os.Stderr
+		if daemonLog != "" {
+			f, err := os.OpenFile(daemonLog, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+			if err != nil {
+				return fmt.Errorf("open log: %w", err)
+			}
+			defer f.Close()
+			logOut = f
+		}
+
+		d := &daemon.Daemon{
+			Browsers: browsers,
+			Interval: daemonInterval,
+			Poll:     daemonPoll,
+			NoBackup: daemonNoBackup,
+			Log:      log.New(logOut, "histctl: ", log.LstdFlags),
+			Load: func() ([]rules.Rule, browser.Protector, error) {
+				rs, err := rules.Load(daemonRules)
+				if err != nil {
+					return nil, nil, err
+				}
+				cfg, err := config.Load(configFlag)
+				if err != nil {
+					return nil, nil, err
+				}
+				return rs, cfg, nil
+			},
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return d.Run(ctx)
+	},
+}
+
+var unitCmd = &cobra.Command{
+	Use:   "unit",
+	Short: "Print (or install) a systemd user unit running the daemon",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := daemon.CheckIntervals(daemonInterval, daemonPoll); err != nil {
+			return err
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		// Pass on every flag the user set explicitly. systemd does not run
+		// the unit in the current directory, so file paths are made absolute.
+		daemonArgs := []string{"daemon"}
+		for _, name := range []string{"browser", "config", "rules", "interval", "poll", "no-backup", "log"} {
+			f := cmd.Flags().Lookup(name)
+			if f == nil || !f.Changed {
+				continue
+			}
+			value := f.Value.String()
+			if (name == "config" || name == "rules" || name == "log") && value != "" {
+				if value, err = filepath.Abs(value); err != nil {
+					return err
+				}
+			}
+			daemonArgs = append(daemonArgs, "--"+name+"="+value)
+		}
+		unit := daemon.Unit(exe, daemonArgs)
+
+		if !unitInstall {
+			fmt.Print(unit)
+			return nil
+		}
+		path, err := daemon.UnitPath()
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(unit), 0644); err != nil {
+			return fmt.Errorf("write unit: %w", err)
+		}
+		fmt.Printf("wrote %s\nenable it with: systemctl --user daemon-reload && systemctl --user enable --now %s\n",
+			path, daemon.UnitName)
+		return nil
+	},
+}
+
+func init() {
+	daemonCmd.PersistentFlags().StringVar(&daemonRules, "rules", "", "Rules file (default $XDG_CONFIG_HOME/histctl/rules.toml)")
+	daemonCmd.PersistentFlags().DurationVar(&daemonInterval, "interval", time.Hour, "Time between scheduled runs")
+	daemonCmd.PersistentFlags().DurationVar(&daemonPoll, "poll", 30*time.Second, "How often to check whether browsers exited")
+	daemonCmd.PersistentFlags().BoolVar(&daemonNoBackup, "no-backup", false, "Skip creating backups")
+	daemonCmd.PersistentFlags().StringVar(&daemonLog, "log", "", "Append log lines to this file instead of stderr")
+	unitCmd.Flags().BoolVar(&unitInstall, "install", false, "Write the unit to ~/.config/systemd/user instead of printing it")
+	daemonCmd.AddCommand(unitCmd)
+	rootCmd.AddCommand(daemonCmd)
+}
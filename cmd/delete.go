@@ -2,128 +2,617 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"time"
 
+	"github.com/odysa/histctl/internal/archive"
 	"github.com/odysa/histctl/internal/backup"
 	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/config"
+	"github.com/odysa/histctl/internal/domain"
+	"github.com/odysa/histctl/internal/plan"
 	"github.com/odysa/histctl/internal/process"
+	"github.com/odysa/histctl/internal/session"
 	"github.com/spf13/cobra"
 )
 
 var (
-	deleteDryRun  bool
-	deleteYes     bool
+	deleteDryRun   bool
+	deleteYes      bool
 	deleteNoBackup bool
+	deleteCookies  bool
+
+	deleteIncludeBookmarked bool
+	deletePlan              string
+	deleteJSON              bool
+	deleteSecure            bool
+	deleteSessions          string
+	deleteDomain            string
+	deleteFields            fieldFlags
+	deleteFilter            string
+	deleteArchive           string
 )
 
 var deleteCmd = &cobra.Command{
-	Use:   "delete <pattern>",
+	Use:   "delete [pattern]",
 	Short: "Delete history entries matching a regex pattern",
-	Args:  cobra.ExactArgs(1),
+	Long: `Delete history entries matching a regex pattern, a --domain, field
+regexes such as --title and a --filter expression. All given matchers must
+match.
+
+Exit codes: 0 success, 1 error, 2 nothing matched, 3 some browsers failed,
+4 a browser was running and skipped.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		pattern, err := regexp.Compile("(?i)" + args[0])
+		fields, err := deleteFields.matches()
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 && deleteDomain == "" && len(fields) == 0 && deleteFilter == "" {
+			return fmt.Errorf("give a pattern, --domain, --filter or a field such as --title")
+		}
+		listOpts := browser.ListOptions{Fields: fields}
+		var patternArg string
+		if len(args) > 0 {
+			patternArg = args[0]
+			pattern, err := regexp.Compile("(?i)" + patternArg)
+			if err != nil {
+				return fmt.Errorf("invalid regex: %w", err)
+			}
+			listOpts.Pattern = pattern
+		}
+		if deleteDomain != "" {
+			d, err := domain.Normalize(deleteDomain)
+			if err != nil {
+				return err
+			}
+			listOpts.Domain = d
+		}
+		if err := applyFilter(&listOpts, deleteFilter); err != nil {
+			return err
+		}
+		if deleteSessions != "" && deleteSessions != "entries" && deleteSessions != "files" {
+			return fmt.Errorf("invalid --sessions %q: want entries or files", deleteSessions)
+		}
+		if deleteArchive != "" && deletePlan != "" {
+			// apply has no archive step, so the planned visits would be lost.
+			return fmt.Errorf("--archive cannot be used with --plan: run `histctl archive sync` before applying the plan")
+		}
+
+		browsers, err := targetBrowsers()
 		if err != nil {
-			return fmt.Errorf("invalid regex: %w", err)
+			return err
 		}
 
-		browsers, err := resolveBrowsers()
+		cfg, err := config.Load(configFlag)
 		if err != nil {
 			return err
 		}
 
-		ctx := context.Background()
-		var hadErrors bool
+		run := &deleteRun{
+			ctx: context.Background(),
+			opts: browser.DeleteOptions{
+				ListOptions:       listOpts,
+				IncludeBookmarked: deleteIncludeBookmarked,
+				Protect:           cfg,
+				Secure:            deleteSecure,
+			},
+			out: os.Stdout,
+		}
+		if deleteJSON {
+			run.out = io.Discard
+		}
+		if deleteArchive != "" && !deleteDryRun {
+			path, err := archivePath(deleteArchive)
+			if err != nil {
+				return err
+			}
+			if run.archive, err = archive.Open(path); err != nil {
+				return err
+			}
+			defer run.archive.Close()
+		}
 
+		var reports []browserReport
 		for _, b := range browsers {
-			// Check if running
-			running, err := process.IsRunning(b.ProcessName())
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "warning: could not check if %s is running: %v\n", b.Name(), err)
-				continue
-			}
-			if running {
-				fmt.Fprintf(os.Stderr, "error: %s is running — close it first\n", b.Name())
-				hadErrors = true
-				continue
-			}
-
-			if deleteDryRun {
-				result, err := b.Delete(ctx, pattern, true)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "error: %s: %v\n", b.Name(), err)
-					hadErrors = true
-					continue
-				}
-				fmt.Printf("[%s] would delete %d entries\n", b.Name(), result.Matched)
-
-				// Show matching entries
-				entries, _ := b.List(ctx, browser.ListOptions{Pattern: pattern, Limit: 20})
-				for _, e := range entries {
-					fmt.Printf("  %s  %s\n", e.URL, e.VisitTime.Local().Format("2006-01-02 15:04"))
-				}
-				if result.Matched > 20 {
-					fmt.Printf("  ... and %d more\n", result.Matched-20)
-				}
-				continue
-			}
-
-			// Preview count
-			result, err := b.Delete(ctx, pattern, true)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "error: %s: %v\n", b.Name(), err)
-				hadErrors = true
-				continue
-			}
-			if result.Matched == 0 {
-				fmt.Printf("[%s] no matching entries\n", b.Name())
-				continue
-			}
-
-			// Confirm
-			if !deleteYes {
-				fmt.Printf("[%s] delete %d entries? (y/N): ", b.Name(), result.Matched)
-				var answer string
-				fmt.Scanln(&answer)
-				if answer != "y" && answer != "Y" {
-					fmt.Println("  skipped")
-					continue
-				}
-			}
-
-			// Backup
-			if !deleteNoBackup {
-				dbPath, _ := b.DBPath()
-				backupPath, err := backup.Create(dbPath)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "error: backup failed for %s: %v\n", b.Name(), err)
-					hadErrors = true
-					continue
-				}
-				fmt.Printf("[%s] backed up to %s\n", b.Name(), backupPath)
-			}
-
-			// Delete
-			result, err = b.Delete(ctx, pattern, false)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "error: %s: %v\n", b.Name(), err)
-				hadErrors = true
-				continue
+			rep := run.browser(b)
+			if rep.Error != "" && !deleteJSON {
+				fmt.Fprintf(os.Stderr, "error: %s: %s\n", b.Name(), rep.Error)
+			}
+			reports = append(reports, rep)
+		}
+
+		if deletePlan != "" {
+			f := plan.File{
+				Version:  plan.Version,
+				Created:  time.Now().UTC(),
+				Pattern:  patternArg,
+				Domain:   listOpts.Domain,
+				Fields:   deleteFields.values(),
+				Filter:   deleteFilter,
+				Browsers: run.planned,
 			}
-			fmt.Printf("[%s] deleted %d entries\n", b.Name(), result.Deleted)
+			if err := plan.Write(deletePlan, f); err != nil {
+				return err
+			}
+			fmt.Fprintf(run.out, "wrote plan to %s — run `histctl apply %s` to execute it\n", deletePlan, deletePlan)
 		}
 
-		if hadErrors {
-			os.Exit(1)
+		code := deleteExitCode(reports)
+		if deleteJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(deleteReport{DryRun: deleteDryRun, Plan: deletePlan, Browsers: reports, ExitCode: code}); err != nil {
+				return err
+			}
+		}
+		if code != exitOK {
+			os.Exit(code)
 		}
 		return nil
 	},
 }
 
+// deleteReport is the --json output of delete.
+type deleteReport struct {
+	DryRun   bool            `json:"dry_run"`
+	Plan     string          `json:"plan,omitempty"`
+	Browsers []browserReport `json:"browsers"`
+	ExitCode int             `json:"exit_code"`
+}
+
+// browserReport is what delete did to one browser.
+type browserReport struct {
+	Browser   string                 `json:"browser"`
+	Matched   int                    `json:"matched"`
+	Deleted   int                    `json:"deleted"`
+	Protected int                    `json:"protected"`
+	Backup    string                 `json:"backup,omitempty"`
+	Archived  int                    `json:"archived,omitempty"` // visits new to the archive
+	Skipped   string                 `json:"skipped,omitempty"`  // running, declined or not found
+	Error     string                 `json:"error,omitempty"`
+	Residue   []browser.Residue      `json:"residue,omitempty"` // --secure only
+	Cookies   *cookieReport          `json:"cookies,omitempty"`
+	Sessions  *sessionReport         `json:"sessions,omitempty"`
+	Entries   []browser.HistoryEntry `json:"entries,omitempty"` // dry run only
+}
+
+// addError records err from the step what, keeping any error recorded
+// before it.
+func (r *browserReport) addError(what string, err error) {
+	msg := fmt.Sprintf("%s: %v", what, err)
+	if r.Error != "" {
+		msg = r.Error + "; " + msg
+	}
+	r.Error = msg
+}
+
+type cookieReport struct {
+	Matched int    `json:"matched"`
+	Deleted int    `json:"deleted"`
+	Backup  string `json:"backup,omitempty"`
+}
+
+type sessionReport struct {
+	Matched int                 `json:"matched"`
+	Removed int                 `json:"removed"`
+	Files   []sessionFileReport `json:"files,omitempty"`
+}
+
+type sessionFileReport struct {
+	Path    string `json:"path"`
+	Matched int    `json:"matched"`
+	Removed int    `json:"removed"`
+	Deleted bool   `json:"deleted,omitempty"` // the whole file was removed
+	Kept    string `json:"kept,omitempty"`    // why a whole file was kept
+	Backup  string `json:"backup,omitempty"`
+}
+
+// deleteRun carries the state shared by every browser of one delete.
+type deleteRun struct {
+	ctx     context.Context
+	opts    browser.DeleteOptions
+	out     io.Writer // human-readable output; discarded with --json
+	planned []plan.Browser
+	archive *archive.Archive // with --archive, where matches go before deleting
+}
+
+func (r *deleteRun) browser(b browser.Browser) browserReport {
+	rep := browserReport{Browser: b.Name()}
+	out := r.out
+
+	if _, err := b.DBPath(); err != nil {
+		rep.Skipped = "not found"
+		if !deleteJSON {
+			fmt.Fprintf(os.Stderr, "error: %s is not installed or history not found: %v\n", b.Name(), err)
+		}
+		return rep
+	}
+
+	// Preview
+	preview := r.opts
+	preview.DryRun = true
+	result, err := b.Delete(r.ctx, preview)
+	if err != nil {
+		rep.Error = err.Error()
+		return rep
+	}
+	rep.Matched = result.Matched
+	rep.Protected = len(result.Protected)
+
+	if deletePlan != "" {
+		pb, err := plan.NewBrowser(b, result.Entries, deleteIncludeBookmarked)
+		if err != nil {
+			rep.Error = err.Error()
+			return rep
+		}
+		r.planned = append(r.planned, pb)
+		fmt.Fprintf(out, "[%s] planned %d entries\n", b.Name(), result.Matched)
+		printProtected(out, b, result.Protected)
+		return rep
+	}
+
+	// Only writing needs the browser closed
+	running, err := process.IsRunning(b.ProcessName())
+	if err != nil {
+		rep.Error = fmt.Sprintf("could not check if running: %v", err)
+		return rep
+	}
+	if running {
+		rep.Skipped = "running"
+		if !deleteJSON {
+			fmt.Fprintf(os.Stderr, "error: %s is running — close it first\n", b.Name())
+		}
+		return rep
+	}
+
+	if deleteDryRun {
+		rep.Entries = result.Entries
+		fmt.Fprintf(out, "[%s] would delete %d entries\n", b.Name(), result.Matched)
+
+		// Show matching entries
+		for i, e := range result.Entries {
+			if i == 20 {
+				fmt.Fprintf(out, "  ... and %d more\n", result.Matched-20)
+				break
+			}
+			fmt.Fprintf(out, "  %s  %s\n", e.URL, e.VisitTime.Local().Format("2006-01-02 15:04"))
+		}
+		printProtected(out, b, result.Protected)
+		if deleteCookies && result.Matched > 0 {
+			if err := r.cleanCookies(b, browser.Hosts(result.Entries), true, &rep); err != nil {
+				rep.addError("cookies", err)
+			}
+		}
+		if deleteSessions != "" {
+			if err := r.cleanSessions(b, true, false, &rep); err != nil {
+				rep.addError("sessions", err)
+			}
+		}
+		return rep
+	}
+
+	if result.Matched == 0 {
+		fmt.Fprintf(out, "[%s] no matching entries\n", b.Name())
+		printProtected(out, b, result.Protected)
+		// Closed tabs can outlive history that was already deleted.
+		if deleteSessions != "" {
+			if err := r.cleanSessions(b, false, !deleteYes, &rep); err != nil {
+				rep.addError("sessions", err)
+			}
+		}
+		return rep
+	}
+
+	// Confirm
+	if !deleteYes {
+		if len(result.Protected) > 0 {
+			fmt.Fprintf(r.prompt(), "[%s] keeping %d protected entries (use --dry-run to list them)\n", b.Name(), len(result.Protected))
+		}
+		if !r.confirm(fmt.Sprintf("[%s] delete %d entries?", b.Name(), result.Matched)) {
+			rep.Skipped = "declined"
+			return rep
+		}
+	}
+
+	// Archive
+	if r.archive != nil {
+		added, err := r.archive.Add(r.ctx, result.Entries)
+		if err != nil {
+			rep.Error = fmt.Sprintf("archive failed: %v", err)
+			return rep
+		}
+		rep.Archived = added.Added
+		fmt.Fprintf(out, "[%s] archived %d new visits to %s\n", b.Name(), added.Added, r.archive.Path())
+	}
+
+	// Backup
+	if !deleteNoBackup {
+		dbPath, _ := b.DBPath()
+		rep.Backup, err = backup.Create(dbPath)
+		if err != nil {
+			rep.Error = fmt.Sprintf("backup failed: %v", err)
+			return rep
+		}
+		fmt.Fprintf(out, "[%s] backed up to %s\n", b.Name(), rep.Backup)
+	}
+
+	// Delete exactly the entries that were previewed and confirmed
+	confirmed := r.opts
+	confirmed.Entries = result.Entries
+	result, err = b.Delete(r.ctx, confirmed)
+	if err != nil {
+		rep.Error = err.Error()
+		return rep
+	}
+	rep.Deleted = result.Deleted
+	fmt.Fprintf(out, "[%s] deleted %d entries\n", b.Name(), result.Deleted)
+	if deleteSecure {
+		rep.Residue = result.Residue
+		printResidue(out, b, result.Residue, rep.Backup)
+	}
+
+	if deleteCookies {
+		if err := r.cleanCookies(b, browser.Hosts(result.Entries), false, &rep); err != nil {
+			rep.addError("cookies", err)
+		}
+	}
+	if deleteSessions != "" {
+		if err := r.cleanSessions(b, false, false, &rep); err != nil {
+			rep.addError("sessions", err)
+		}
+	}
+	return rep
+}
+
+// prompt is where questions go; stdout stays clean for the JSON report.
+func (r *deleteRun) prompt() io.Writer {
+	if deleteJSON {
+		return os.Stderr
+	}
+	return r.out
+}
+
+// confirm asks a yes/no question and reports whether the answer was yes.
+func (r *deleteRun) confirm(question string) bool {
+	fmt.Fprintf(r.prompt(), "%s (y/N): ", question)
+	var answer string
+	fmt.Scanln(&answer)
+	if answer != "y" && answer != "Y" {
+		fmt.Fprintln(r.prompt(), "  skipped")
+		return false
+	}
+	return true
+}
+
+// printProtected lists matches that were shielded from deletion.
+func printProtected(w io.Writer, b browser.Browser, protected []browser.ProtectedEntry) {
+	if len(protected) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "[%s] protected %d entries\n", b.Name(), len(protected))
+	for i, p := range protected {
+		if i == 20 {
+			fmt.Fprintf(w, "  ... and %d more\n", len(protected)-20)
+			break
+		}
+		fmt.Fprintf(w, "  %s  %s  (%s)\n", p.URL, p.VisitTime.Local().Format("2006-01-02 15:04"), p.Reason)
+	}
+}
+
+// printResidue reports what a secure delete left behind on disk.
+func printResidue(w io.Writer, b browser.Browser, residue []browser.Residue, backupPath string) {
+	if len(residue) == 0 {
+		fmt.Fprintf(w, "[%s] secure delete: no deleted URLs left in the database files\n", b.Name())
+	} else {
+		fmt.Fprintf(w, "[%s] secure delete: %d deleted URLs still on disk\n", b.Name(), len(residue))
+		for _, r := range residue {
+			fmt.Fprintf(w, "  %s  in %s\n", r.URL, r.Path)
+		}
+	}
+	if backupPath != "" {
+		fmt.Fprintf(w, "[%s] note: backup %s still contains the deleted entries\n", b.Name(), backupPath)
+	}
+}
+
+// cleanCookies removes the cookies of hosts from the browser's cookie store.
+// The cookie database is backed up first unless --no-backup is set.
+func (r *deleteRun) cleanCookies(b browser.Browser, hosts []string, dryRun bool, rep *browserReport) error {
+	out := r.out
+	cc, ok := b.(browser.CookieCleaner)
+	if !ok {
+		if !deleteJSON {
+			fmt.Fprintf(os.Stderr, "warning: cookie cleanup is not supported for %s\n", b.Name())
+		}
+		return nil
+	}
+
+	result, err := cc.DeleteCookies(r.ctx, hosts, true)
+	if err != nil {
+		return err
+	}
+	rep.Cookies = &cookieReport{Matched: result.Matched}
+	if result.Matched == 0 {
+		fmt.Fprintf(out, "[%s] no matching cookies\n", b.Name())
+		return nil
+	}
+	if dryRun {
+		fmt.Fprintf(out, "[%s] would delete %d cookies\n", b.Name(), result.Matched)
+		for _, h := range hosts {
+			if n := result.ByHost[h]; n > 0 {
+				fmt.Fprintf(out, "  %s  %d\n", h, n)
+			}
+		}
+		return nil
+	}
+
+	if !deleteNoBackup {
+		cookiesPath, _ := cc.CookiesPath()
+		rep.Cookies.Backup, err = backup.Create(cookiesPath)
+		if err != nil {
+			return fmt.Errorf("backup failed: %w", err)
+		}
+		fmt.Fprintf(out, "[%s] backed up cookies to %s\n", b.Name(), rep.Cookies.Backup)
+	}
+
+	result, err = cc.DeleteCookies(r.ctx, hosts, false)
+	if err != nil {
+		return err
+	}
+	rep.Cookies.Deleted = result.Deleted
+	fmt.Fprintf(out, "[%s] deleted %d cookies\n", b.Name(), result.Deleted)
+	return nil
+}
+
+// cleanSessions removes matching tabs from the browser's session files, or
+// with --sessions=files the whole files. Protected URLs are kept, and a file
+// holding one is edited rather than removed. Each changed file is backed up
+// first unless --no-backup is set; ask prompts before changing anything.
+func (r *deleteRun) cleanSessions(b browser.Browser, dryRun, ask bool, rep *browserReport) error {
+	out := r.out
+	sl, ok := b.(browser.SessionLister)
+	if !ok {
+		if !deleteJSON {
+			fmt.Fprintf(os.Stderr, "warning: session cleanup is not supported for %s\n", b.Name())
+		}
+		return nil
+	}
+	files, err := sl.SessionFiles()
+	if err != nil {
+		return err
+	}
+
+	protected := func(e session.Entry) bool {
+		return r.opts.Protect != nil && r.opts.Protect.Protects(browser.HistoryEntry{URL: e.URL, Title: e.Title, Browser: b.Name()}) != ""
+	}
+	inPattern := func(e session.Entry) bool {
+		return r.opts.Matches(browser.HistoryEntry{URL: e.URL, Title: e.Title, Browser: b.Name()})
+	}
+	match := func(e session.Entry) bool { return inPattern(e) && !protected(e) }
+
+	rep.Sessions = &sessionReport{}
+	for _, path := range files {
+		matched, err := session.Match(path, match)
+		if errors.Is(err, session.ErrUnknownFormat) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		fr := sessionFileReport{Path: path, Matched: len(matched)}
+		if deleteSessions == "files" {
+			if kept, err := session.Match(path, func(e session.Entry) bool { return inPattern(e) && protected(e) }); err != nil {
+				return err
+			} else if len(kept) > 0 {
+				fr.Kept = "protected"
+			}
+		}
+		rep.Sessions.Matched += fr.Matched
+		rep.Sessions.Files = append(rep.Sessions.Files, fr)
+	}
+
+	if rep.Sessions.Matched == 0 {
+		fmt.Fprintf(out, "[%s] no matching session entries\n", b.Name())
+		return nil
+	}
+	if dryRun {
+		fmt.Fprintf(out, "[%s] would remove %d session entries\n", b.Name(), rep.Sessions.Matched)
+		for _, fr := range rep.Sessions.Files {
+			verb := "edit"
+			if deleteSessions == "files" && fr.Kept == "" {
+				verb = "delete file"
+			}
+			fmt.Fprintf(out, "  %s  %d  (%s)\n", fr.Path, fr.Matched, verb)
+		}
+		return nil
+	}
+	if ask && !r.confirm(fmt.Sprintf("[%s] remove %d session entries?", b.Name(), rep.Sessions.Matched)) {
+		rep.Skipped = "declined"
+		return nil
+	}
+
+	for i := range rep.Sessions.Files {
+		fr := &rep.Sessions.Files[i]
+		if !deleteNoBackup {
+			if fr.Backup, err = backup.Create(fr.Path); err != nil {
+				return fmt.Errorf("backup failed: %w", err)
+			}
+		}
+		if deleteSessions == "files" && fr.Kept == "" {
+			if err := os.Remove(fr.Path); err != nil {
+				return err
+			}
+			fr.Removed, fr.Deleted = fr.Matched, true
+		} else if fr.Removed, err = session.Remove(fr.Path, match); err != nil {
+			return err
+		}
+		rep.Sessions.Removed += fr.Removed
+	}
+	fmt.Fprintf(out, "[%s] removed %d session entries from %d files\n", b.Name(), rep.Sessions.Removed, len(rep.Sessions.Files))
+	for _, fr := range rep.Sessions.Files {
+		switch {
+		case fr.Deleted:
+			fmt.Fprintf(out, "  deleted %s\n", fr.Path)
+		case fr.Kept != "":
+			fmt.Fprintf(out, "  edited %s (kept: contains protected entries)\n", fr.Path)
+		}
+	}
+	return nil
+}
+
+// deleteExitCode condenses per-browser reports into the process exit code.
+func deleteExitCode(reports []browserReport) int {
+	var failed, running, ok, matched int
+	for _, r := range reports {
+		switch {
+		case r.Error != "", r.Skipped == "not found":
+			failed++
+		case r.Skipped == "running":
+			running++
+		default:
+			ok++
+		}
+		matched += r.Matched
+		if r.Sessions != nil {
+			matched += r.Sessions.Matched
+		}
+	}
+	switch {
+	case failed > 0 && ok+running > 0:
+		return exitPartial
+	case failed > 0:
+		return exitError
+	case running > 0:
+		return exitRunning
+	case matched == 0:
+		return exitNothingMatched
+	}
+	return exitOK
+}
+
 func init() {
 	deleteCmd.Flags().BoolVarP(&deleteDryRun, "dry-run", "d", false, "Preview matches without deleting")
 	deleteCmd.Flags().BoolVarP(&deleteYes, "yes", "y", false, "Skip confirmation prompt")
 	deleteCmd.Flags().BoolVar(&deleteNoBackup, "no-backup", false, "Skip creating a backup")
+	deleteCmd.Flags().BoolVar(&deleteCookies, "cookies", false, "Also delete cookies for hosts of matched entries")
+	deleteCmd.Flags().BoolVar(&deleteIncludeBookmarked, "include-bookmarked", false, "Also delete visits of bookmarked pages")
+	deleteCmd.Flags().StringVar(&deletePlan, "plan", "", "Write the matching entries to a plan file instead of deleting")
+	deleteCmd.Flags().BoolVar(&deleteJSON, "json", false, "Output a JSON report")
+	deleteCmd.Flags().BoolVar(&deleteSecure, "secure", false, "Overwrite deleted data, vacuum, and verify no URLs remain on disk")
+	deleteCmd.Flags().StringVar(&deleteSessions, "sessions", "", "Also remove matching tabs from session files: entries, or files to delete whole files")
+	deleteCmd.Flags().Lookup("sessions").NoOptDefVal = "entries"
+	deleteCmd.Flags().StringVar(&deleteDomain, "domain", "", "Match entries on this domain or its subdomains instead of, or with, a pattern")
+	deleteFields = addFieldFlags(deleteCmd)
+	deleteCmd.Flags().StringVar(&deleteFilter, "filter", "", "Filter expression such as 'host:github.com AND NOT path:/settings'")
+	deleteCmd.Flags().StringVar(&deleteArchive, "archive", "", "Archive matched visits before deleting them, into the default archive or this database")
+	deleteCmd.Flags().Lookup("archive").NoOptDefVal = archiveDefault
 	rootCmd.AddCommand(deleteCmd)
 }
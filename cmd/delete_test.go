@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeleteExitCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		reports []browserReport
+		want    int
+	}{
+		{"success", []browserReport{{Browser: "chrome", Matched: 2, Deleted: 2}, {Browser: "firefox"}}, exitOK},
+		{"sessions only", []browserReport{{Browser: "chrome", Sessions: &sessionReport{Matched: 1}}}, exitOK},
+		{"nothing matched", []browserReport{{Browser: "chrome"}, {Browser: "firefox"}}, exitNothingMatched},
+		{"declined", []browserReport{{Browser: "chrome", Matched: 2, Skipped: "declined"}}, exitOK},
+		{"partial failure", []browserReport{{Browser: "chrome", Matched: 2, Deleted: 2}, {Browser: "firefox", Error: "backup failed"}}, exitPartial},
+		{"all failed", []browserReport{{Browser: "chrome", Error: "locked"}, {Browser: "firefox", Error: "locked"}}, exitError},
+		{"running", []browserReport{{Browser: "chrome", Skipped: "running"}, {Browser: "firefox", Matched: 1, Deleted: 1}}, exitRunning},
+		{"not found", []browserReport{{Browser: "safari", Skipped: "not found"}}, exitError},
+		{"not found and running", []browserReport{{Browser: "safari", Skipped: "not found"}, {Browser: "chrome", Skipped: "running"}}, exitPartial},
+	}
+	for _, tt := range tests {
+		if got := deleteExitCode(tt.reports); got != tt.want {
+			t.Errorf("%s: deleteExitCode() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBrowserReportAddError(t *testing.T) {
+	var rep browserReport
+	rep.addError("cookies", errors.New("locked"))
+	rep.addError("sessions", errors.New("corrupt"))
+	if want := "cookies: locked; sessions: corrupt"; rep.Error != want {
+		t.Errorf("Error = %q, want %q", rep.Error, want)
+	}
+}
+
+func TestDeleteRejectsArchiveWithPlan(t *testing.T) {
+	deleteArchive, deletePlan = archiveDefault, filepath.Join(t.TempDir(), "plan.json")
+	t.Cleanup(func() { deleteArchive, deletePlan = "", "" })
+	err := deleteCmd.RunE(deleteCmd, []string{"example"})
+	if err == nil || !strings.Contains(err.Error(), "--archive cannot be used with --plan") {
+		t.Errorf("RunE() error = %v, want --archive rejected with --plan", err)
+	}
+	if _, err := os.Stat(deletePlan); !os.IsNotExist(err) {
+		t.Errorf("plan file written: %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+package cmd
+
+// Exit codes reported by delete.
+const (
+	exitOK             = 0
+	exitError          = 1 // every targeted browser failed
+	exitNothingMatched = 2
+	exitPartial        = 3 // some browsers failed, others succeeded
+	exitRunning        = 4 // a browser was running and left untouched
+)
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/query"
+	"github.com/spf13/cobra"
+)
+
+// fieldFlags holds the --url, --title, --host, --path and --query regexes
+// of one command.
+type fieldFlags map[browser.Field]*string
+
+func addFieldFlags(cmd *cobra.Command) fieldFlags {
+	flags := make(fieldFlags)
+	for _, f := range browser.Fields {
+		name := string(f)
+		if f == browser.FieldURL {
+			name = "URL"
+		}
+		flags[f] = cmd.Flags().String(string(f), "", fmt.Sprintf("Regex matched against the %s only", name))
+	}
+	return flags
+}
+
+// matches compiles the flags that were set, in browser.Fields order.
+func (ff fieldFlags) matches() ([]browser.FieldMatch, error) {
+	var out []browser.FieldMatch
+	for _, f := range browser.Fields {
+		if *ff[f] == "" {
+			continue
+		}
+		m, err := browser.NewFieldMatch(f, *ff[f])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// values returns the flags that were set, for reports.
+func (ff fieldFlags) values() map[string]string {
+	var out map[string]string
+	for f, v := range ff {
+		if *v != "" {
+			if out == nil {
+				out = make(map[string]string)
+			}
+			out[string(f)] = *v
+		}
+	}
+	return out
+}
+
+// applyFilter ANDs a --filter expression into opts. Query errors are shown
+// with the expression and a caret under the bad token.
+func applyFilter(opts *browser.ListOptions, expr string) error {
+	if expr == "" {
+		return nil
+	}
+	q, err := query.Compile(expr, time.Now())
+	if err != nil {
+		var qe *query.Error
+		if errors.As(err, &qe) {
+			return fmt.Errorf("invalid --filter: %w\n%s", err, qe.Context())
+		}
+		return err
+	}
+	*opts = query.Merge(*opts, q)
+	return nil
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/odysa/histctl/internal/backup"
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/export"
+	"github.com/odysa/histctl/internal/process"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importInto     string
+	importDryRun   bool
+	importNoBackup bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import --into <browser> <file>...",
+	Short: "Import history from exported files into a browser",
+	Long: `Import history exported with list --format csv or ndjson, or list --json,
+into the history database of a browser (chrome, edge or firefox).
+
+Each entry becomes a visit. URLs the browser does not know get a new page,
+and visits already there (same URL and time) are skipped, so importing a
+file twice adds nothing. The browser must be closed; its database is backed
+up first.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		b, err := browser.Get(importInto)
+		if err != nil {
+			return err
+		}
+		importer, ok := b.(browser.Importer)
+		if !ok {
+			return fmt.Errorf("importing into %s is not supported", b.Name())
+		}
+		dbPath, err := b.DBPath()
+		if err != nil {
+			return fmt.Errorf("%s is not installed or history not found: %w", b.Name(), err)
+		}
+
+		var entries []browser.HistoryEntry
+		invalid := 0
+		for _, path := range args {
+			read, err := export.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			for _, e := range read {
+				if e.URL == "" || e.VisitTime.IsZero() {
+					invalid++
+					continue
+				}
+				entries = append(entries, e)
+			}
+		}
+		if invalid > 0 {
+			fmt.Fprintf(os.Stderr, "warning: skipping %d entries without a URL or visit time\n", invalid)
+		}
+		return importEntries(b, importer, dbPath, entries, importDryRun, !importNoBackup)
+	},
+}
+
+// importEntries adds entries to b, checking that it is closed and backing
+// up its database first.
+func importEntries(b browser.Browser, importer browser.Importer, dbPath string, entries []browser.HistoryEntry, dryRun, backUp bool) error {
+	ctx := context.Background()
+	preview, err := importer.Import(ctx, entries, true)
+	if err != nil {
+		return err
+	}
+	if dryRun || preview.Added == 0 {
+		verb := "would import"
+		if !dryRun {
+			verb = "nothing to import:"
+		}
+		fmt.Printf("[%s] %s %d visits (%d new pages), %d already present\n",
+			b.Name(), verb, preview.Added, preview.Pages, preview.Skipped)
+		return nil
+	}
+
+	running, err := process.IsRunning(b.ProcessName())
+	if err != nil {
+		return fmt.Errorf("could not check if %s is running: %w", b.Name(), err)
+	}
+	if running {
+		return fmt.Errorf("%s is running — close it first", b.Name())
+	}
+	if backUp {
+		path, err := backup.Create(dbPath)
+		if err != nil {
+			return fmt.Errorf("backup failed: %w", err)
+		}
+		fmt.Printf("[%s] backed up to %s\n", b.Name(), path)
+	}
+	result, err := importer.Import(ctx, entries, false)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("[%s] imported %d visits (%d new pages), %d already present\n",
+		b.Name(), result.Added, result.Pages, result.Skipped)
+	return nil
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importInto, "into", "", "Browser to import into: chrome|edge|firefox")
+	importCmd.Flags().BoolVarP(&importDryRun, "dry-run", "d", false, "Count what would be imported without writing")
+	importCmd.Flags().BoolVar(&importNoBackup, "no-backup", false, "Skip backup")
+	importCmd.MarkFlagRequired("into")
+	rootCmd.AddCommand(importCmd)
+}
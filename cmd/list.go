@@ -1,20 +1,32 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/domain"
+	"github.com/odysa/histctl/internal/export"
+	"github.com/odysa/histctl/internal/group"
 	"github.com/spf13/cobra"
 )
 
 var (
 	listLimit  int
 	listJSON   bool
+	listDomain string
+	listFields fieldFlags
+	listFilter string
+	listGroup  string
+	listFormat string
+	listOutput string
 )
 
 var listCmd = &cobra.Command{
@@ -35,8 +47,47 @@ var listCmd = &cobra.Command{
 			}
 			opts.Pattern = re
 		}
+		if listDomain != "" {
+			d, err := domain.Normalize(listDomain)
+			if err != nil {
+				return err
+			}
+			opts.Domain = d
+		}
+		if opts.Fields, err = listFields.matches(); err != nil {
+			return err
+		}
+		if err := applyFilter(&opts, listFilter); err != nil {
+			return err
+		}
+		var groupBy group.Key
+		if listGroup != "" {
+			if groupBy, err = group.ParseKey(listGroup); err != nil {
+				return err
+			}
+			// Count every visit; the limit applies to groups instead.
+			opts.Limit = 0
+		}
+		format := listFormat
+		if format == "" && listOutput != "" {
+			if format = export.ForPath(listOutput); format == "" {
+				return fmt.Errorf("cannot tell the format of %s from its extension; use --format %s",
+					listOutput, strings.Join(export.Formats(), "|"))
+			}
+		}
 
 		ctx := context.Background()
+		if format != "" {
+			if listJSON || groupBy != "" {
+				return fmt.Errorf("--format cannot be combined with --json or --group")
+			}
+			newWriter, err := listWriter(format)
+			if err != nil {
+				return err
+			}
+			return exportList(ctx, browsers, opts, newWriter, listOutput)
+		}
+
 		var all []browser.HistoryEntry
 		for _, b := range browsers {
 			entries, err := b.List(ctx, opts)
@@ -47,33 +98,139 @@ var listCmd = &cobra.Command{
 			all = append(all, entries...)
 		}
 
+		if groupBy != "" {
+			return printGroups(all, groupBy)
+		}
+
 		if listJSON {
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
 			return enc.Encode(all)
 		}
 
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "BROWSER\tURL\tTITLE\tTIME")
-		for _, e := range all {
-			title := e.Title
-			if len(title) > 40 {
-				title = title[:39] + "…"
-			}
-			url := e.URL
-			if len(url) > 60 {
-				url = url[:59] + "…"
+		return printEntries(all)
+	},
+}
+
+// printEntries prints one row per visit, with long URLs and titles cut.
+func printEntries(entries []browser.HistoryEntry) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BROWSER\tURL\tTITLE\tTIME")
+	for _, e := range entries {
+		title := e.Title
+		if len(title) > 40 {
+			title = title[:39] + "…"
+		}
+		url := e.URL
+		if len(url) > 60 {
+			url = url[:59] + "…"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			e.Browser, url, title,
+			e.VisitTime.Local().Format("2006-01-02 15:04"))
+	}
+	return w.Flush()
+}
+
+// listWriter returns the writer for a --format value: an export format,
+// or a template executed once per entry.
+func listWriter(format string) (func(io.Writer) export.Writer, error) {
+	if !isTemplate(format) {
+		return export.Lookup(format)
+	}
+	tmpl, err := parseListTemplate(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format template: %w", err)
+	}
+	return export.Template(tmpl), nil
+}
+
+// exportList writes the entries of each browser with newWriter as they
+// are listed, to path or to stdout if path is empty.
+func exportList(ctx context.Context, browsers []browser.Browser, opts browser.ListOptions, newWriter func(io.Writer) export.Writer, path string) error {
+	out := os.Stdout
+	bw := bufio.NewWriter(out)
+	w := newWriter(bw)
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+		bw.Reset(f)
+	}
+
+	count := 0
+	for _, b := range browsers {
+		entries, err := b.List(ctx, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s: %v\n", b.Name(), err)
+			continue
+		}
+		for _, e := range entries {
+			if err := w.Write(e); err != nil {
+				return err
 			}
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
-				e.Browser, url, title,
-				e.VisitTime.Local().Format("2006-01-02 15:04"))
 		}
-		return w.Flush()
-	},
+		count += len(entries)
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if path != "" {
+		if err := out.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "exported %d entries to %s\n", count, path)
+	}
+	return nil
+}
+
+// printGroups prints one row per URL or host with its visit count, first
+// and last visit and browsers.
+func printGroups(entries []browser.HistoryEntry, key group.Key) error {
+	groups := group.By(entries, key)
+	if listLimit > 0 && len(groups) > listLimit {
+		groups = groups[:listLimit]
+	}
+	if listJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(groups)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "VISITS\t%s\tTITLE\tFIRST\tLAST\tBROWSERS\n", strings.ToUpper(string(key)))
+	for _, g := range groups {
+		k := g.Key
+		if len(k) > 60 {
+			k = k[:59] + "…"
+		}
+		title := g.Title
+		if len(title) > 40 {
+			title = title[:39] + "…"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			g.Visits, k, title,
+			g.First.Local().Format("2006-01-02 15:04"),
+			g.Last.Local().Format("2006-01-02 15:04"),
+			strings.Join(g.Browsers, ","))
+	}
+	return w.Flush()
 }
 
 func init() {
 	listCmd.Flags().IntVarP(&listLimit, "limit", "n", 50, "Max entries to display")
 	listCmd.Flags().BoolVar(&listJSON, "json", false, "Output as JSON")
+	listCmd.Flags().StringVar(&listDomain, "domain", "", "Only entries on this domain or its subdomains")
+	listFields = addFieldFlags(listCmd)
+	listCmd.Flags().StringVar(&listGroup, "group", "", "Aggregate visits by url or host; --limit then counts groups")
+	listCmd.Flags().StringVar(&listFormat, "format", "", "Export format (csv, ndjson, html, markdown) or a Go template such as '{{.Browser}} {{.URL}}'")
+	listCmd.Flags().StringVarP(&listOutput, "output", "o", "", "Write to this file; the format defaults to its extension")
+	listCmd.Flags().StringVar(&listFilter, "filter", "", "Filter expression such as 'host:github.com AND visits>3'")
 	rootCmd.AddCommand(listCmd)
 }
@@ -5,11 +5,15 @@ import (
 	"os"
 
 	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/config"
 	"github.com/odysa/histctl/internal/tui"
 	"github.com/spf13/cobra"
 )
 
-var browserFlag string
+var (
+	browserFlag string
+	configFlag  string
+)
 
 var rootCmd = &cobra.Command{
 	Use:   "histctl",
@@ -24,13 +28,19 @@ var rootCmd = &cobra.Command{
 			fmt.Fprintln(os.Stderr, "No supported browsers found.")
 			return nil
 		}
-		return tui.Run(browsers)
+		cfg, err := config.Load(configFlag)
+		if err != nil {
+			return err
+		}
+		return tui.Run(browsers, cfg)
 	},
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&browserFlag, "browser", "b", "all",
-		"Target browser: safari|chrome|edge|firefox|all")
+		"Target browser: safari|chrome|edge|firefox|all, or an export such as takeout:<file>")
+	rootCmd.PersistentFlags().StringVar(&configFlag, "config", "",
+		"Config file (default $XDG_CONFIG_HOME/histctl/config.toml)")
 }
 
 func Execute() {
@@ -52,3 +62,16 @@ func resolveBrowsers() ([]browser.Browser, error) {
 	}
 	return []browser.Browser{b}, nil
 }
+
+// targetBrowsers is like resolveBrowsers but keeps an explicitly named
+// browser even if its history is missing, so callers can report it.
+func targetBrowsers() ([]browser.Browser, error) {
+	if browserFlag == "all" {
+		return browser.Available(), nil
+	}
+	b, err := browser.Get(browserFlag)
+	if err != nil {
+		return nil, err
+	}
+	return []browser.Browser{b}, nil
+}
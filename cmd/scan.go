@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"text/tabwriter"
+
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/scan"
+	"github.com/spf13/cobra"
+)
+
+var scanJSON bool
+
+var scanCmd = &cobra.Command{
+	Use:   "scan <pattern>",
+	Short: "Find URLs that survive on disk in history-adjacent files",
+	Long: `Search the raw bytes of every history-adjacent file of a profile — the
+database with its WAL, journal and .bak backups, favicon and top-site
+caches, and session files — for URLs matching pattern. URLs still present
+in live history are marked "live"; the rest are residue of deleted history.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		re, err := regexp.Compile("(?i)" + args[0])
+		if err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+		browsers, err := resolveBrowsers()
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		var reports []scanReport
+		var hadErrors bool
+		for _, b := range browsers {
+			rep := scanBrowser(ctx, b, re)
+			if rep.Error != "" {
+				fmt.Fprintf(os.Stderr, "warning: %s: %s\n", b.Name(), rep.Error)
+				hadErrors = true
+			}
+			reports = append(reports, rep)
+		}
+
+		if scanJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(reports); err != nil {
+				return err
+			}
+		} else {
+			for _, rep := range reports {
+				printScan(rep)
+			}
+		}
+		if hadErrors {
+			os.Exit(exitError)
+		}
+		return nil
+	},
+}
+
+// scanReport is the scan result for one browser.
+type scanReport struct {
+	Browser string        `json:"browser"`
+	Files   []string      `json:"files"`
+	Found   []scanFinding `json:"found"`
+	Residue int           `json:"residue"` // findings not in live history
+	Error   string        `json:"error,omitempty"`
+}
+
+// scanFinding groups the hits of one URL in one file.
+type scanFinding struct {
+	Path   string `json:"path"`
+	URL    string `json:"url"`
+	Hits   int    `json:"hits"`
+	Offset int64  `json:"offset"` // of the first hit
+	Live   bool   `json:"live"`
+}
+
+func scanBrowser(ctx context.Context, b browser.Browser, re *regexp.Regexp) scanReport {
+	rep := scanReport{Browser: b.Name()}
+	al, ok := b.(browser.ArtifactLister)
+	if !ok {
+		rep.Error = "scanning is not supported"
+		return rep
+	}
+	files, err := al.Artifacts()
+	if err != nil {
+		rep.Error = err.Error()
+		return rep
+	}
+	rep.Files = files
+
+	entries, err := b.List(ctx, browser.ListOptions{Pattern: re})
+	if err != nil {
+		rep.Error = err.Error()
+		return rep
+	}
+	live := make(map[string]string, len(entries))
+	for _, e := range entries {
+		if title, ok := live[e.URL]; !ok || title == "" {
+			live[e.URL] = e.Title
+		}
+	}
+
+	hits, err := scan.Files(files, re)
+	if err != nil {
+		rep.Error = err.Error()
+	}
+	index := make(map[[2]string]int)
+	for _, h := range hits {
+		u, isLive := scan.Live(h.URL, live)
+		key := [2]string{h.Path, u}
+		if i, ok := index[key]; ok {
+			rep.Found[i].Hits++
+			continue
+		}
+		index[key] = len(rep.Found)
+		rep.Found = append(rep.Found, scanFinding{Path: h.Path, URL: u, Hits: 1, Offset: h.Offset, Live: isLive})
+		if !isLive {
+			rep.Residue++
+		}
+	}
+	return rep
+}
+
+func printScan(rep scanReport) {
+	if rep.Error != "" && len(rep.Files) == 0 {
+		return
+	}
+	fmt.Printf("[%s] scanned %d files: %d matching URLs, %d not in live history\n",
+		rep.Browser, len(rep.Files), len(rep.Found), rep.Residue)
+	if len(rep.Found) == 0 {
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  FILE\tOFFSET\tHITS\tSTATE\tURL")
+	for _, f := range rep.Found {
+		state := "residue"
+		if f.Live {
+			state = "live"
+		}
+		fmt.Fprintf(w, "  %s\t%#x\t%d\t%s\t%s\n", filepath.Base(f.Path), f.Offset, f.Hits, state, f.URL)
+	}
+	w.Flush()
+}
+
+func init() {
+	scanCmd.Flags().BoolVar(&scanJSON, "json", false, "Output a JSON report")
+	rootCmd.AddCommand(scanCmd)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"text/tabwriter"
+
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var sessionsJSON bool
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions [pattern]",
+	Short: "List URLs kept in session and recently-closed-tab files",
+	Long: `List the URLs of open and recently closed tabs stored in session files
+(Chrome and Edge Sessions/Session_* and Tabs_*, Firefox sessionstore).
+These survive history deletes; remove them with delete --sessions.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		match := func(session.Entry) bool { return true }
+		if len(args) > 0 {
+			re, err := regexp.Compile("(?i)" + args[0])
+			if err != nil {
+				return fmt.Errorf("invalid regex: %w", err)
+			}
+			match = session.URLMatcher(re)
+		}
+		browsers, err := resolveBrowsers()
+		if err != nil {
+			return err
+		}
+
+		var all []sessionListing
+		for _, b := range browsers {
+			sl, ok := b.(browser.SessionLister)
+			if !ok {
+				continue
+			}
+			files, err := sl.SessionFiles()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %s: %v\n", b.Name(), err)
+				continue
+			}
+			for _, path := range files {
+				entries, err := session.Match(path, match)
+				if errors.Is(err, session.ErrUnknownFormat) {
+					continue
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "warning: %s: %v\n", b.Name(), err)
+					continue
+				}
+				for _, e := range entries {
+					all = append(all, sessionListing{Browser: b.Name(), File: path, Entry: e})
+				}
+			}
+		}
+
+		if sessionsJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(all)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "BROWSER\tFILE\tURL\tTITLE")
+		for _, l := range all {
+			title := l.Title
+			if len(title) > 40 {
+				title = title[:39] + "…"
+			}
+			url := l.URL
+			if len(url) > 60 {
+				url = url[:59] + "…"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", l.Browser, filepath.Base(l.File), url, title)
+		}
+		return w.Flush()
+	},
+}
+
+// sessionListing is one session entry in the sessions output.
+type sessionListing struct {
+	Browser string `json:"browser"`
+	File    string `json:"file"`
+	session.Entry
+}
+
+func init() {
+	sessionsCmd.Flags().BoolVar(&sessionsJSON, "json", false, "Output as JSON")
+	rootCmd.AddCommand(sessionsCmd)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsTop    int
+	statsDays   int
+	statsFilter string
+	statsJSON   bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize browsing: top domains, activity by day and hour, per-browser totals",
+	Long: `Summarize history across the selected browsers: visits per browser, the
+most visited domains, visits per day and an hour-of-week heatmap. Narrow
+the history with --filter, e.g. --filter after:90d.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		browsers, err := resolveBrowsers()
+		if err != nil {
+			return err
+		}
+		var opts browser.ListOptions
+		if err := applyFilter(&opts, statsFilter); err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		var all []browser.HistoryEntry
+		for _, b := range browsers {
+			entries, err := b.List(ctx, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %s: %v\n", b.Name(), err)
+				continue
+			}
+			all = append(all, entries...)
+		}
+		s := stats.Compute(all, statsTop)
+
+		if statsJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(s)
+		}
+		printStats(s)
+		return nil
+	},
+}
+
+func printStats(s stats.Stats) {
+	if s.Visits == 0 {
+		fmt.Println("no history")
+		return
+	}
+	heading := lipgloss.NewStyle().Bold(true)
+	border := lipgloss.NewStyle().Foreground(lipgloss.Color("#4A4A4A"))
+	newTable := func(headers ...string) *table.Table {
+		return table.New().Border(lipgloss.RoundedBorder()).BorderStyle(border).Headers(headers...).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				style := lipgloss.NewStyle().Padding(0, 1)
+				if row == table.HeaderRow {
+					return style.Bold(true)
+				}
+				return style
+			})
+	}
+
+	fmt.Printf("%d visits from %s to %s\n\n", s.Visits,
+		s.First.Local().Format("2006-01-02 15:04"), s.Last.Local().Format("2006-01-02 15:04"))
+
+	bt := newTable("BROWSER", "VISITS", "SHARE")
+	for _, c := range s.Browsers {
+		bt.Row(c.Name, fmt.Sprint(c.Visits), fmt.Sprintf("%.1f%%", 100*float64(c.Visits)/float64(s.Visits)))
+	}
+	fmt.Println(bt.Render())
+
+	if len(s.TopDomains) > 0 {
+		fmt.Println("\n" + heading.Render("Top domains"))
+		dt := newTable("#", "DOMAIN", "VISITS", "")
+		peak := s.TopDomains[0].Visits
+		for i, c := range s.TopDomains {
+			dt.Row(fmt.Sprint(i+1), c.Name, fmt.Sprint(c.Visits), stats.Bar(c.Visits, peak, 30))
+		}
+		fmt.Println(dt.Render())
+	}
+
+	days := s.Days
+	if statsDays > 0 && len(days) > statsDays {
+		days = days[len(days)-statsDays:]
+	}
+	values := make([]int, len(days))
+	busiest := days[0]
+	for i, d := range days {
+		values[i] = d.Visits
+		if d.Visits > busiest.Visits {
+			busiest = d
+		}
+	}
+	fmt.Println("\n" + heading.Render(fmt.Sprintf("Visits per day, %s to %s", days[0].Date, days[len(days)-1].Date)))
+	fmt.Println(lipgloss.NewStyle().Foreground(stats.HeatColors[3]).Render(stats.Sparkline(values)))
+	fmt.Printf("busiest %s with %d visits\n", busiest.Date, busiest.Visits)
+
+	fmt.Println("\n" + heading.Render("Visits by hour of the week ("+time.Now().Format("MST")+")"))
+	fmt.Println(stats.Heatmap(s.HourOfWeek))
+}
+
+func init() {
+	statsCmd.Flags().IntVar(&statsTop, "top", 10, "Number of top domains to show")
+	statsCmd.Flags().IntVar(&statsDays, "days", 60, "Days shown in the per-day chart (0 for all; --json always has all)")
+	statsCmd.Flags().StringVar(&statsFilter, "filter", "", "Filter expression limiting the history, e.g. 'after:90d'")
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Output as JSON")
+	rootCmd.AddCommand(statsCmd)
+}
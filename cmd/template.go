@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/domain"
+	"github.com/odysa/histctl/internal/tui"
+)
+
+// templateFuncs are the helpers available to list --format templates.
+var templateFuncs = template.FuncMap{
+	// host returns the host of a URL, or "" if it has none.
+	"host": func(rawURL string) string {
+		return browser.FieldHost.Value(browser.HistoryEntry{URL: rawURL})
+	},
+	// domain returns the registrable domain of a URL, e.g. bbc.co.uk.
+	"domain": func(rawURL string) string {
+		if host := browser.FieldHost.Value(browser.HistoryEntry{URL: rawURL}); host != "" {
+			return domain.Registrable(host)
+		}
+		return ""
+	},
+	// truncate shortens s to n characters, ending in "…"; it takes s last
+	// so that {{.Title | truncate 40}} works.
+	"truncate": func(n int, s string) string {
+		if n <= 0 || utf8.RuneCountInString(s) <= n {
+			return s
+		}
+		return string([]rune(s)[:n-1]) + "…"
+	},
+	// ago is the relative time the TUI shows, such as "5m ago".
+	"ago": tui.RelativeTime,
+}
+
+// isTemplate reports whether a --format value is a template rather than
+// the name of an export format.
+func isTemplate(format string) bool {
+	return strings.Contains(format, "{{")
+}
+
+// templateEscapes lets shell-quoted templates spell tabs and newlines.
+var templateEscapes = strings.NewReplacer(`\t`, "\t", `\n`, "\n")
+
+// unescapeTemplate replaces the escapes in the text of a template, leaving
+// its {{ }} actions alone so "\n" in a string literal keeps its meaning.
+func unescapeTemplate(text string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(text, "{{")
+		if start < 0 {
+			b.WriteString(templateEscapes.Replace(text))
+			return b.String()
+		}
+		b.WriteString(templateEscapes.Replace(text[:start]))
+		text = text[start:]
+		end := strings.Index(text, "}}")
+		if end < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		b.WriteString(text[:end+2])
+		text = text[end+2:]
+	}
+}
+
+// parseListTemplate parses a list --format template, ending it with a
+// newline unless it already ends with one.
+func parseListTemplate(text string) (*template.Template, error) {
+	text = unescapeTemplate(text)
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return template.New("format").Funcs(templateFuncs).Parse(text)
+}
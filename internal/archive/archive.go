@@ -0,0 +1,233 @@
+// Package archive keeps histctl's own long-term record of browser history
+// in a SQLite database, so visits stay searchable after they are deleted
+// from the browsers.
+package archive
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+	_ "modernc.org/sqlite"
+)
+
+// schema stores one row per visit, keyed by browser, URL and visit time,
+// and the text of cached pages, one row per URL. FTS5 indexes over them
+// are kept in sync by triggers.
+const schema = `
+CREATE TABLE IF NOT EXISTS visits (
+	id INTEGER PRIMARY KEY,
+	browser TEXT NOT NULL,
+	url TEXT NOT NULL,
+	title TEXT NOT NULL DEFAULT '',
+	visit_time INTEGER NOT NULL, -- microseconds since the Unix epoch
+	visit_count INTEGER NOT NULL DEFAULT 0,
+	archived_at INTEGER NOT NULL,
+	UNIQUE (browser, url, visit_time)
+);
+CREATE INDEX IF NOT EXISTS visits_visit_time ON visits (visit_time);
+CREATE INDEX IF NOT EXISTS visits_url ON visits (url);
+CREATE VIRTUAL TABLE IF NOT EXISTS visits_fts USING fts5(
+	url, title, content='visits', content_rowid='id',
+	tokenize='unicode61 remove_diacritics 2'
+);
+CREATE TRIGGER IF NOT EXISTS visits_ai AFTER INSERT ON visits BEGIN
+	INSERT INTO visits_fts (rowid, url, title) VALUES (new.id, new.url, new.title);
+END;
+CREATE TRIGGER IF NOT EXISTS visits_ad AFTER DELETE ON visits BEGIN
+	INSERT INTO visits_fts (visits_fts, rowid, url, title) VALUES ('delete', old.id, old.url, old.title);
+END;
+CREATE TABLE IF NOT EXISTS pages (
+	id INTEGER PRIMARY KEY,
+	url TEXT NOT NULL UNIQUE,
+	title TEXT NOT NULL DEFAULT '',
+	text TEXT NOT NULL,
+	indexed_at INTEGER NOT NULL
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS pages_fts USING fts5(
+	url, title, text, content='pages', content_rowid='id',
+	tokenize='unicode61 remove_diacritics 2'
+);
+CREATE TRIGGER IF NOT EXISTS pages_ai AFTER INSERT ON pages BEGIN
+	INSERT INTO pages_fts (rowid, url, title, text) VALUES (new.id, new.url, new.title, new.text);
+END;
+CREATE TRIGGER IF NOT EXISTS pages_ad AFTER DELETE ON pages BEGIN
+	INSERT INTO pages_fts (pages_fts, rowid, url, title, text) VALUES ('delete', old.id, old.url, old.title, old.text);
+END;
+CREATE TRIGGER IF NOT EXISTS pages_au AFTER UPDATE ON pages BEGIN
+	INSERT INTO pages_fts (pages_fts, rowid, url, title, text) VALUES ('delete', old.id, old.url, old.title, old.text);
+	INSERT INTO pages_fts (rowid, url, title, text) VALUES (new.id, new.url, new.title, new.text);
+END;`
+
+// Archive is an open archive database.
+type Archive struct {
+	db   *sql.DB
+	path string
+}
+
+// DefaultPath returns archive.db in $XDG_DATA_HOME/histctl, falling back to
+// ~/.local/share on Linux and the user config directory elsewhere.
+func DefaultPath() (string, error) {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		var err error
+		if runtime.GOOS == "linux" {
+			var home string
+			home, err = os.UserHomeDir()
+			dir = filepath.Join(home, ".local", "share")
+		} else {
+			dir, err = os.UserConfigDir()
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, "histctl", "archive.db"), nil
+}
+
+// Open opens the archive at path, creating it and its directory if needed.
+func Open(path string) (*Archive, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("create archive directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open archive %s: %w", path, err)
+	}
+	// The archive holds history the browsers no longer have; keep it private.
+	if err := os.Chmod(path, 0600); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Archive{db: db, path: path}, nil
+}
+
+// Path returns the database file of the archive.
+func (a *Archive) Path() string { return a.path }
+
+// Close closes the database.
+func (a *Archive) Close() error { return a.db.Close() }
+
+// AddResult summarizes an Add.
+type AddResult struct {
+	Added   int // visits new to the archive
+	Skipped int // visits it already had
+}
+
+// Add archives entries. A visit already archived for the same browser, URL
+// and visit time is skipped, so adding the same history again is cheap and
+// adds nothing.
+func (a *Archive) Add(ctx context.Context, entries []browser.HistoryEntry) (AddResult, error) {
+	var result AddResult
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO visits (browser, url, title, visit_time, visit_count, archived_at)
+		VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (browser, url, visit_time) DO NOTHING`)
+	if err != nil {
+		return result, err
+	}
+	defer stmt.Close()
+	now := time.Now().UnixMicro()
+	for _, e := range entries {
+		res, err := stmt.ExecContext(ctx, e.Browser, e.URL, e.Title, e.VisitTime.UnixMicro(), e.VisitCount, now)
+		if err != nil {
+			return result, fmt.Errorf("archive %s: %w", e.URL, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			result.Added++
+		} else {
+			result.Skipped++
+		}
+	}
+	return result, tx.Commit()
+}
+
+// Count returns the number of archived visits.
+func (a *Archive) Count(ctx context.Context) (int, error) {
+	var n int
+	err := a.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM visits").Scan(&n)
+	return n, err
+}
+
+// SearchOptions narrows a Search.
+type SearchOptions struct {
+	Browser string // only visits from this browser; "" for all
+	Limit   int    // 0 means no limit
+}
+
+// Search returns archived visits whose URL or title, or the indexed text of
+// their page, contain every word of query, best matches first and newer
+// visits before older ones. The last word also matches as a prefix, so a
+// query can be typed incrementally.
+func (a *Archive) Search(ctx context.Context, query string, opts SearchOptions) ([]browser.HistoryEntry, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, fmt.Errorf("empty search")
+	}
+	q := `WITH hits (id, rank) AS (
+			SELECT rowid, rank FROM visits_fts WHERE visits_fts MATCH ?1
+			UNION ALL
+			SELECT v.id, pages_fts.rank FROM pages_fts
+				JOIN pages p ON p.id = pages_fts.rowid
+				JOIN visits v ON v.url = p.url
+			WHERE pages_fts MATCH ?1
+		)
+		SELECT v.browser, v.url, v.title, v.visit_time, v.visit_count
+		FROM (SELECT id, MIN(rank) AS rank FROM hits GROUP BY id) h
+		JOIN visits v ON v.id = h.id`
+	args := []any{match}
+	if opts.Browser != "" {
+		q += " WHERE v.browser = ?"
+		args = append(args, opts.Browser)
+	}
+	q += " ORDER BY h.rank, v.visit_time DESC"
+	if opts.Limit > 0 {
+		q += " LIMIT ?"
+		args = append(args, opts.Limit)
+	}
+	rows, err := a.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search archive: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []browser.HistoryEntry
+	for rows.Next() {
+		var e browser.HistoryEntry
+		var ts int64
+		if err := rows.Scan(&e.Browser, &e.URL, &e.Title, &ts, &e.VisitCount); err != nil {
+			return nil, err
+		}
+		e.VisitTime = time.UnixMicro(ts)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ftsQuery turns words into an FTS5 query matching all of them. Each word
+// is quoted, so punctuation in URLs such as "github.com" is matched as a
+// phrase of its parts instead of being read as query syntax.
+func ftsQuery(query string) string {
+	words := strings.Fields(query)
+	for i, w := range words {
+		words[i] = `"` + strings.ReplaceAll(w, `"`, `""`) + `"`
+	}
+	if len(words) > 0 {
+		words[len(words)-1] += "*"
+	}
+	return strings.Join(words, " ")
+}
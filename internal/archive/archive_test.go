@@ -0,0 +1,167 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+)
+
+var testEntries = []browser.HistoryEntry{
+	{URL: "https://go.dev/blog/scheduler", Title: "The Go Scheduler", VisitTime: time.UnixMicro(1_700_000_000_000_000), Browser: "chrome"},
+	{URL: "https://go.dev/blog/scheduler", Title: "The Go Scheduler", VisitTime: time.UnixMicro(1_700_000_100_000_000), Browser: "chrome"},
+	{URL: "https://github.com/golang/go", Title: "golang/go", VisitTime: time.UnixMicro(1_700_000_200_000_000), Browser: "firefox"},
+	{URL: "https://example.com/café", Title: "Café", VisitTime: time.UnixMicro(1_700_000_300_000_000), Browser: "firefox"},
+}
+
+func openTest(t *testing.T) *Archive {
+	t.Helper()
+	a, err := Open(filepath.Join(t.TempDir(), "histctl", "archive.db"))
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	t.Cleanup(func() { a.Close() })
+	return a
+}
+
+func TestAdd(t *testing.T) {
+	a := openTest(t)
+	ctx := context.Background()
+	result, err := a.Add(ctx, testEntries)
+	if err != nil {
+		t.Fatalf("Add() error: %v", err)
+	}
+	if want := (AddResult{Added: 4}); result != want {
+		t.Errorf("Add() = %+v, want %+v", result, want)
+	}
+
+	// The same visit from another browser is a separate record.
+	again := append(testEntries[:1:1], browser.HistoryEntry{URL: testEntries[0].URL, VisitTime: testEntries[0].VisitTime, Browser: "edge"})
+	result, err = a.Add(ctx, again)
+	if err != nil {
+		t.Fatalf("second Add() error: %v", err)
+	}
+	if want := (AddResult{Added: 1, Skipped: 1}); result != want {
+		t.Errorf("second Add() = %+v, want %+v", result, want)
+	}
+	if n, err := a.Count(ctx); err != nil || n != 5 {
+		t.Errorf("Count() = %d, %v; want 5", n, err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	a := openTest(t)
+	ctx := context.Background()
+	if _, err := a.Add(ctx, testEntries); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		query string
+		opts  SearchOptions
+		want  int
+	}{
+		{"scheduler", SearchOptions{}, 2},
+		{"go sched", SearchOptions{}, 2},   // the last word is a prefix
+		{"github.com", SearchOptions{}, 1}, // punctuation is not syntax
+		{"golang", SearchOptions{}, 1},     // URL and title
+		{"cafe", SearchOptions{}, 1},       // diacritics are folded
+		{"go", SearchOptions{Browser: "firefox"}, 1},
+		{"scheduler", SearchOptions{Limit: 1}, 1},
+		{`"unbalanced`, SearchOptions{}, 0},
+		{"rust", SearchOptions{}, 0},
+	}
+	for _, tt := range tests {
+		entries, err := a.Search(ctx, tt.query, tt.opts)
+		if err != nil {
+			t.Errorf("Search(%q) error: %v", tt.query, err)
+			continue
+		}
+		if len(entries) != tt.want {
+			t.Errorf("Search(%q, %+v) = %d entries, want %d", tt.query, tt.opts, len(entries), tt.want)
+		}
+	}
+
+	entries, _ := a.Search(ctx, "scheduler", SearchOptions{})
+	if len(entries) == 2 && !entries[0].VisitTime.After(entries[1].VisitTime) {
+		t.Errorf("equally good matches should be newest first: %v, %v", entries[0].VisitTime, entries[1].VisitTime)
+	}
+	if _, err := a.Search(ctx, "  ", SearchOptions{}); err == nil {
+		t.Error("Search() of nothing should fail")
+	}
+}
+
+// cache2Entry builds a Firefox cache2 entry for a body of less than one
+// chunk.
+func cache2Entry(url, head, body string) []byte {
+	var b bytes.Buffer
+	b.WriteString(body)
+	b.Write(make([]byte, 4+2)) // metadata hash and one chunk hash
+	hdr := make([]byte, 32)
+	binary.BigEndian.PutUint32(hdr, 3)
+	binary.BigEndian.PutUint32(hdr[24:], uint32(len(":"+url)))
+	b.Write(hdr)
+	b.WriteString(":" + url + "\x00response-head\x00" + head + "\x00")
+	binary.Write(&b, binary.BigEndian, uint32(len(body)))
+	return b.Bytes()
+}
+
+func TestIndexCache(t *testing.T) {
+	a := openTest(t)
+	ctx := context.Background()
+	entries := append(testEntries, browser.HistoryEntry{
+		URL: "https://go.dev/blog/scheduler#preemption", VisitTime: time.UnixMicro(1_700_000_400_000_000), Browser: "firefox",
+	})
+	if _, err := a.Add(ctx, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	html := "HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n"
+	for name, data := range map[string][]byte{
+		"page":      cache2Entry("https://go.dev/blog/scheduler", html, "<p>Goroutines are multiplexed onto threads</p>"),
+		"unvisited": cache2Entry("https://elsewhere.example/", html, "<p>threads</p>"),
+		"image":     cache2Entry("https://example.com/café", "HTTP/1.1 200 OK\r\nContent-Type: image/png\r\n", "threads"),
+		"garbage":   []byte("not a cache entry"),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := a.IndexCache(ctx, dir)
+	if err != nil {
+		t.Fatalf("IndexCache() error: %v", err)
+	}
+	// The page is indexed for the URL and for the URL with a fragment.
+	if want := (IndexResult{Entries: 3, Pages: 2}); result != want {
+		t.Errorf("IndexCache() = %+v, want %+v", result, want)
+	}
+	if n, err := a.PageCount(ctx); err != nil || n != 2 {
+		t.Errorf("PageCount() = %d, %v; want 2", n, err)
+	}
+
+	found, err := a.Search(ctx, "goroutines multiplexed", SearchOptions{})
+	if err != nil {
+		t.Fatalf("Search() error: %v", err)
+	}
+	if len(found) != 3 {
+		t.Errorf("Search(page text) = %d visits, want the 3 visits of the page", len(found))
+	}
+	// Words may be split between the title and the page text.
+	if found, _ := a.Search(ctx, "scheduler threads", SearchOptions{}); len(found) != 3 {
+		t.Errorf("Search(title and text) = %d visits, want 3", len(found))
+	}
+
+	// Indexing again replaces the text.
+	if _, err := a.IndexCache(ctx, dir); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := a.Search(ctx, "goroutines", SearchOptions{}); len(found) != 3 {
+		t.Errorf("Search() after reindexing = %d visits, want 3", len(found))
+	}
+}
@@ -0,0 +1,119 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/odysa/histctl/internal/pagecache"
+)
+
+// IndexResult summarizes an IndexCache.
+type IndexResult struct {
+	Entries int // cache entries read
+	Pages   int // archived URLs whose page text was indexed
+}
+
+// IndexCache reads the browser disk cache in dir (see browser.Cacher) and
+// indexes the text of every cached page of an archived URL, replacing text
+// indexed before. Cache entries are matched to URLs without their fragment.
+// Entries that are not pages or cannot be read are skipped.
+func (a *Archive) IndexCache(ctx context.Context, dir string) (IndexResult, error) {
+	var result IndexResult
+	urls, titles, err := a.archivedURLs(ctx)
+	if err != nil {
+		return result, err
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return result, err
+	}
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO pages (url, title, text, indexed_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (url) DO UPDATE SET title = excluded.title, text = excluded.text, indexed_at = excluded.indexed_at`)
+	if err != nil {
+		return result, err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UnixMicro()
+	indexed := make(map[string]bool)
+	for _, f := range files {
+		if !f.Type().IsRegular() {
+			continue
+		}
+		e, err := pagecache.Open(filepath.Join(dir, f.Name()))
+		if err != nil {
+			continue
+		}
+		result.Entries++
+		matched := urls[withoutFragment(e.URL)]
+		if len(matched) == 0 {
+			continue
+		}
+		pageTitle, text, err := e.Text()
+		if err != nil || text == "" {
+			continue
+		}
+		for _, url := range matched {
+			title := titles[url]
+			if title == "" {
+				title = pageTitle
+			}
+			if _, err := stmt.ExecContext(ctx, url, title, text, now); err != nil {
+				return result, fmt.Errorf("index %s: %w", url, err)
+			}
+			indexed[url] = true
+		}
+	}
+	result.Pages = len(indexed)
+	return result, tx.Commit()
+}
+
+// archivedURLs returns the archived URLs by their URL without fragment, and
+// the latest title of each.
+func (a *Archive) archivedURLs(ctx context.Context) (map[string][]string, map[string]string, error) {
+	rows, err := a.db.QueryContext(ctx, "SELECT url, title FROM visits ORDER BY visit_time")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	urls := make(map[string][]string)
+	titles := make(map[string]string)
+	for rows.Next() {
+		var url, title string
+		if err := rows.Scan(&url, &title); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := titles[url]; !ok {
+			key := withoutFragment(url)
+			urls[key] = append(urls[key], url)
+			titles[url] = title
+		} else if title != "" {
+			titles[url] = title
+		}
+	}
+	return urls, titles, rows.Err()
+}
+
+// PageCount returns the number of pages with indexed text.
+func (a *Archive) PageCount(ctx context.Context) (int, error) {
+	var n int
+	err := a.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM pages").Scan(&n)
+	return n, err
+}
+
+func withoutFragment(url string) string {
+	if i := strings.IndexByte(url, '#'); i >= 0 {
+		return url[:i]
+	}
+	return url
+}
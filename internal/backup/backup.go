@@ -12,23 +12,44 @@ import (
 func Create(srcPath string) (string, error) {
 	ts := time.Now().Format("20060102-150405")
 	backupPath := fmt.Sprintf("%s.%s.bak", srcPath, ts)
+	if err := copyFile(srcPath, backupPath); err != nil {
+		os.Remove(backupPath)
+		return "", err
+	}
+	return backupPath, nil
+}
+
+// Rolling copies srcPath to srcPath.<name>.bak, replacing the previous
+// backup of that name, so repeated runs keep a single copy.
+// Returns the backup file path.
+func Rolling(srcPath, name string) (string, error) {
+	backupPath := fmt.Sprintf("%s.%s.bak", srcPath, name)
+	tmp := backupPath + ".tmp"
+	if err := copyFile(srcPath, tmp); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, backupPath); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("replace backup: %w", err)
+	}
+	return backupPath, nil
+}
 
+func copyFile(srcPath, dstPath string) error {
 	src, err := os.Open(srcPath)
 	if err != nil {
-		return "", fmt.Errorf("open source for backup: %w", err)
+		return fmt.Errorf("open source for backup: %w", err)
 	}
 	defer src.Close()
 
-	dst, err := os.Create(backupPath)
+	dst, err := os.Create(dstPath)
 	if err != nil {
-		return "", fmt.Errorf("create backup file: %w", err)
+		return fmt.Errorf("create backup file: %w", err)
 	}
-	defer dst.Close()
-
 	if _, err := io.Copy(dst, src); err != nil {
-		os.Remove(backupPath)
-		return "", fmt.Errorf("backup copy failed: %w", err)
+		dst.Close()
+		return fmt.Errorf("backup copy failed: %w", err)
 	}
-
-	return backupPath, nil
+	return dst.Close()
 }
@@ -57,3 +57,26 @@ func TestCreateMissingSource(t *testing.T) {
 		t.Fatal("Create() should fail for missing source file")
 	}
 }
+
+func TestRolling(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "test.db")
+	for _, content := range []string{"first", "second"} {
+		if err := os.WriteFile(src, []byte(content), 0644); err != nil {
+			t.Fatalf("write source: %v", err)
+		}
+		backupPath, err := Rolling(src, "daemon")
+		if err != nil {
+			t.Fatalf("Rolling() error: %v", err)
+		}
+		if want := src + ".daemon.bak"; backupPath != want {
+			t.Errorf("Rolling() = %q, want %q", backupPath, want)
+		}
+		if got, _ := os.ReadFile(backupPath); string(got) != content {
+			t.Errorf("backup content = %q, want %q", got, content)
+		}
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 2 {
+		t.Errorf("%d files in dir, want the source and one backup", len(files))
+	}
+}
@@ -0,0 +1,143 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ArtifactLister is implemented by browsers that know which files besides
+// the live history database can hold visited URLs: WAL and journal files,
+// backups, favicon and top-site caches and session files.
+type ArtifactLister interface {
+	Artifacts() ([]string, error)
+}
+
+// sqliteFiles returns the glob patterns for a database in dir: the file
+// itself, its WAL and rollback journal, and backups written by backup.Create.
+func sqliteFiles(dir, name string) []string {
+	p := filepath.Join(dir, name)
+	return []string{p, p + "-wal", p + "-journal", p + ".*.bak"}
+}
+
+// existingFiles expands patterns and returns the matching regular files,
+// sorted and without duplicates.
+func existingFiles(patterns []string) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, pat := range patterns {
+		matches, _ := filepath.Glob(pat) // only ErrBadPattern, for fixed patterns
+		for _, m := range matches {
+			if seen[m] {
+				continue
+			}
+			if info, err := os.Stat(m); err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			seen[m] = true
+			files = append(files, m)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Artifacts lists the Chrome profile files that record visited URLs.
+func (c *Chrome) Artifacts() ([]string, error) {
+	dbPath, err := c.DBPath()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(dbPath)
+	var patterns []string
+	for _, name := range []string{filepath.Base(dbPath), "Favicons", "Top Sites", "Shortcuts", "Network Action Predictor"} {
+		patterns = append(patterns, sqliteFiles(dir, name)...)
+	}
+	for _, name := range []string{"Visited Links", "Current Session", "Current Tabs", "Last Session", "Last Tabs"} {
+		patterns = append(patterns, filepath.Join(dir, name))
+	}
+	patterns = append(patterns, filepath.Join(dir, "Sessions", "*"))
+	return existingFiles(patterns), nil
+}
+
+// Artifacts lists the Firefox profile files that record visited URLs.
+// Session files are mozlz4 compressed; scan.File decompresses them.
+func (f *Firefox) Artifacts() ([]string, error) {
+	dbPath, err := f.DBPath()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(dbPath)
+	patterns := append(sqliteFiles(dir, filepath.Base(dbPath)), sqliteFiles(dir, "favicons.sqlite")...)
+	patterns = append(patterns,
+		filepath.Join(dir, "sessionstore.jsonlz4"),
+		filepath.Join(dir, "sessionstore-backups", "*"),
+	)
+	return existingFiles(patterns), nil
+}
+
+// Artifacts lists the Safari files that record visited URLs.
+func (s *Safari) Artifacts() ([]string, error) {
+	dbPath, err := s.DBPath()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(dbPath)
+	patterns := sqliteFiles(dir, filepath.Base(dbPath))
+	for _, name := range []string{"TopSites.plist", "LastSession.plist", "RecentlyClosedTabs.plist"} {
+		patterns = append(patterns, filepath.Join(dir, name))
+	}
+	patterns = append(patterns, filepath.Join(dir, "Favicon Cache", "*"))
+	return existingFiles(patterns), nil
+}
+
+// SessionLister is implemented by browsers that keep open and recently
+// closed tabs in session files, which outlive history deletes.
+type SessionLister interface {
+	SessionFiles() ([]string, error)
+}
+
+// sessionFiles expands patterns like existingFiles, leaving out backups.
+func sessionFiles(patterns []string) []string {
+	var files []string
+	for _, f := range existingFiles(patterns) {
+		if !strings.HasSuffix(f, ".bak") {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// SessionFiles lists the Chrome session and tab restore files, including
+// the names used before Chrome 88.
+func (c *Chrome) SessionFiles() ([]string, error) {
+	dbPath, err := c.DBPath()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(dbPath)
+	return sessionFiles([]string{
+		filepath.Join(dir, "Sessions", "Session_*"),
+		filepath.Join(dir, "Sessions", "Tabs_*"),
+		filepath.Join(dir, "Current Session"),
+		filepath.Join(dir, "Last Session"),
+		filepath.Join(dir, "Current Tabs"),
+		filepath.Join(dir, "Last Tabs"),
+	}), nil
+}
+
+// SessionFiles lists the Firefox sessionstore file and its backups.
+func (f *Firefox) SessionFiles() ([]string, error) {
+	dbPath, err := f.DBPath()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(dbPath)
+	return sessionFiles([]string{
+		filepath.Join(dir, "sessionstore.jsonlz4"),
+		filepath.Join(dir, "sessionstore-backups", "*.jsonlz4"),
+		filepath.Join(dir, "sessionstore-backups", "*.baklz4"),
+		filepath.Join(dir, "sessionstore-backups", "upgrade.jsonlz4-*"),
+	}), nil
+}
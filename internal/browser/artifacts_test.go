@@ -0,0 +1,63 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChromeArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{"History", "History-wal", "History.20260101-120000.bak", "Favicons", "Top Sites-journal", filepath.Join("Sessions", "Session_1")}
+	for _, name := range append(files, "Preferences") {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(p, nil, 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "Sessions", "old"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	got, err := NewChrome(filepath.Join(dir, "History")).Artifacts()
+	if err != nil {
+		t.Fatalf("Artifacts() error: %v", err)
+	}
+	want := map[string]bool{}
+	for _, name := range files {
+		want[filepath.Join(dir, name)] = true
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Artifacts() = %v, want %d files", got, len(want))
+	}
+	for _, p := range got {
+		if !want[p] {
+			t.Errorf("Artifacts() listed unexpected %s", p)
+		}
+	}
+}
+
+func TestFirefoxSessionFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{"sessionstore.jsonlz4", filepath.Join("sessionstore-backups", "recovery.jsonlz4"), filepath.Join("sessionstore-backups", "recovery.baklz4")}
+	for _, name := range append(files, "places.sqlite", filepath.Join("sessionstore-backups", "recovery.jsonlz4.20261019-120000.bak")) {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(p, nil, 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	got, err := NewFirefox(filepath.Join(dir, "places.sqlite")).SessionFiles()
+	if err != nil {
+		t.Fatalf("SessionFiles() error: %v", err)
+	}
+	if len(got) != len(files) {
+		t.Fatalf("SessionFiles() = %v, want %d files without backups", got, len(files))
+	}
+}
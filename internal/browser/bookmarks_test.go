@@ -0,0 +1,165 @@
+package browser
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"howett.net/plist"
+)
+
+// bookmarkFirefox adds a moz_bookmarks row pointing at placeID.
+func bookmarkFirefox(t *testing.T, f *Firefox, placeID int64) {
+	t.Helper()
+	dbPath, _ := f.DBPath()
+	db, err := sql.Open("sqlite", "file:"+dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("INSERT INTO moz_bookmarks (type, fk, title) VALUES (1, ?, 'bm')", placeID); err != nil {
+		t.Fatalf("seed moz_bookmarks: %v", err)
+	}
+}
+
+func countRows(t *testing.T, dbPath, query string, args ...any) int {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	var n int
+	if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("count rows: %v", err)
+	}
+	return n
+}
+
+func TestFirefoxDeleteProtectsBookmarks(t *testing.T) {
+	f := newTestFirefox(t, firefoxTestRows)
+	bookmarkFirefox(t, f, 1) // https://example.com
+	ctx := context.Background()
+
+	result, err := f.Delete(ctx, DeleteOptions{})
+	if err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if result.Deleted != 2 {
+		t.Errorf("Delete() Deleted = %d, want 2", result.Deleted)
+	}
+	if len(result.Protected) != 1 || result.Protected[0].URL != "https://example.com" {
+		t.Fatalf("Delete() Protected = %+v, want example.com", result.Protected)
+	}
+	if result.Protected[0].Reason != "bookmarked" {
+		t.Errorf("Protected reason = %q, want %q", result.Protected[0].Reason, "bookmarked")
+	}
+
+	entries, _ := f.List(ctx, ListOptions{})
+	if len(entries) != 1 {
+		t.Errorf("after delete, List() returned %d entries, want 1", len(entries))
+	}
+}
+
+func TestFirefoxDeleteIncludeBookmarked(t *testing.T) {
+	f := newTestFirefox(t, firefoxTestRows)
+	bookmarkFirefox(t, f, 1)
+	ctx := context.Background()
+
+	re := regexp.MustCompile(`example\.com`)
+	result, err := f.Delete(ctx, DeleteOptions{ListOptions: ListOptions{Pattern: re}, IncludeBookmarked: true})
+	if err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if result.Deleted != 1 || len(result.Protected) != 0 {
+		t.Errorf("Delete() = {Deleted: %d, Protected: %d}, want {1, 0}", result.Deleted, len(result.Protected))
+	}
+
+	dbPath, _ := f.DBPath()
+	if n := countRows(t, dbPath, "SELECT COUNT(*) FROM moz_historyvisits WHERE place_id = 1"); n != 0 {
+		t.Errorf("%d visits remain for bookmarked place, want 0", n)
+	}
+	if n := countRows(t, dbPath, "SELECT COUNT(*) FROM moz_places WHERE id = 1"); n != 1 {
+		t.Errorf("bookmarked place was removed; bookmark would dangle")
+	}
+}
+
+func TestChromeBookmarks(t *testing.T) {
+	c := newTestChrome(t, chromeTestRows)
+	dbPath, _ := c.DBPath()
+	data := `{
+		"checksum": "x",
+		"roots": {
+			"bookmark_bar": {"type": "folder", "children": [
+				{"type": "url", "url": "https://golang.org"},
+				{"type": "folder", "children": [{"type": "url", "url": "https://github.com"}]}
+			]},
+			"other": {"type": "folder", "children": []},
+			"sync_transaction_version": "1"
+		},
+		"version": 1
+	}`
+	if err := os.WriteFile(filepath.Join(filepath.Dir(dbPath), "Bookmarks"), []byte(data), 0644); err != nil {
+		t.Fatalf("write Bookmarks: %v", err)
+	}
+	ctx := context.Background()
+
+	bookmarks, err := c.Bookmarks(ctx)
+	if err != nil {
+		t.Fatalf("Bookmarks() error: %v", err)
+	}
+	if len(bookmarks) != 2 || !bookmarks["https://golang.org"] || !bookmarks["https://github.com"] {
+		t.Errorf("Bookmarks() = %v, want golang.org and github.com", bookmarks)
+	}
+
+	result, err := c.Delete(ctx, DeleteOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Delete(dryRun) error: %v", err)
+	}
+	if result.Matched != 1 || len(result.Protected) != 2 {
+		t.Errorf("Delete(dryRun) = {Matched: %d, Protected: %d}, want {1, 2}", result.Matched, len(result.Protected))
+	}
+}
+
+func TestChromeBookmarksMissing(t *testing.T) {
+	c := newTestChrome(t, chromeTestRows)
+	bookmarks, err := c.Bookmarks(context.Background())
+	if err != nil {
+		t.Fatalf("Bookmarks() error: %v", err)
+	}
+	if len(bookmarks) != 0 {
+		t.Errorf("Bookmarks() = %v, want empty", bookmarks)
+	}
+}
+
+func TestSafariBookmarks(t *testing.T) {
+	s := newTestSafari(t, safariTestRows)
+	dbPath, _ := s.DBPath()
+	root := safariBookmark{
+		Type: "WebBookmarkTypeList",
+		Children: []safariBookmark{
+			{Type: "WebBookmarkTypeList", Children: []safariBookmark{
+				{Type: "WebBookmarkTypeLeaf", URLString: "https://example.com"},
+			}},
+		},
+	}
+	data, err := plist.Marshal(root, plist.BinaryFormat)
+	if err != nil {
+		t.Fatalf("marshal plist: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(dbPath), "Bookmarks.plist"), data, 0644); err != nil {
+		t.Fatalf("write Bookmarks.plist: %v", err)
+	}
+
+	re := regexp.MustCompile(`example\.com`)
+	result, err := s.Delete(context.Background(), DeleteOptions{ListOptions: ListOptions{Pattern: re}})
+	if err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if result.Deleted != 0 || len(result.Protected) != 1 {
+		t.Errorf("Delete() = {Deleted: %d, Protected: %d}, want {0, 1}", result.Deleted, len(result.Protected))
+	}
+}
@@ -14,20 +14,69 @@ type HistoryEntry struct {
 	VisitCount int       `json:"visit_count,omitempty"`
 	Browser    string    `json:"browser"`
 	ItemID     int64     `json:"-"` // internal: used for deletion
+	VisitID    int64     `json:"-"` // internal: used for deletion
 }
 
 // ListOptions controls filtering when listing history.
 type ListOptions struct {
-	Pattern *regexp.Regexp
-	Limit   int
-	Since   time.Time
-	Until   time.Time
+	Pattern *regexp.Regexp // matched against the URL
+	// Domain, if set, keeps entries whose host is this normalized domain
+	// (see domain.Normalize) or one of its subdomains.
+	Domain string
+	// Fields are further matchers on parts of the entry; all must match.
+	Fields []FieldMatch
+	// Filter, if set, is a further predicate for conditions that have no
+	// field of their own, such as a compiled query's OR and NOT terms.
+	Filter    func(HistoryEntry) bool
+	Limit     int
+	Since     time.Time
+	Until     time.Time
+	MinVisits int // 0 means no minimum
+	MaxVisits int // 0 means no maximum
+}
+
+// DeleteOptions controls which entries Delete removes.
+type DeleteOptions struct {
+	ListOptions
+	// Entries, if non-nil, are deleted as given instead of the entries
+	// matching ListOptions. They must come from the same backend.
+	Entries []HistoryEntry
+	DryRun  bool
+	// IncludeBookmarked deletes the visits of bookmarked pages as well. The
+	// page record itself is always kept so the bookmark keeps pointing at it.
+	IncludeBookmarked bool
+	// Protect, if set, shields entries from deletion.
+	Protect Protector
+	// Secure overwrites deleted data, vacuums the database and checks the
+	// files on disk for leftovers of removed URLs.
+	Secure bool
+}
+
+// Protector decides whether an entry must survive a delete. Protects returns
+// the reason it is kept, or "" if it may be deleted.
+type Protector interface {
+	Protects(e HistoryEntry) string
 }
 
 // DeleteResult summarizes a delete operation.
 type DeleteResult struct {
-	Matched int
-	Deleted int
+	Matched   int
+	Deleted   int
+	Entries   []HistoryEntry   // entries that were (or would be) deleted
+	Protected []ProtectedEntry // matches shielded from deletion
+	Residue   []Residue        // removed URLs still on disk after a secure delete
+}
+
+// Residue is a removed URL that is still readable in a database file.
+type Residue struct {
+	Path string `json:"path"`
+	URL  string `json:"url"`
+}
+
+// ProtectedEntry is a matching entry that was kept, and why.
+type ProtectedEntry struct {
+	HistoryEntry
+	Reason string
 }
 
 // Browser is the interface every browser backend must implement.
@@ -36,5 +85,10 @@ type Browser interface {
 	DBPath() (string, error)
 	ProcessName() string
 	List(ctx context.Context, opts ListOptions) ([]HistoryEntry, error)
-	Delete(ctx context.Context, pattern *regexp.Regexp, dryRun bool) (DeleteResult, error)
+	Delete(ctx context.Context, opts DeleteOptions) (DeleteResult, error)
+}
+
+// Bookmarker is implemented by browsers that can report their bookmarked URLs.
+type Bookmarker interface {
+	Bookmarks(ctx context.Context) (map[string]bool, error)
 }
@@ -0,0 +1,76 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsupportedCache is returned by CacheDir for a disk cache in a format
+// package pagecache cannot read.
+var ErrUnsupportedCache = errors.New("unsupported cache format")
+
+// Cacher is implemented by browsers with a disk cache of the pages they
+// loaded that package pagecache can read.
+type Cacher interface {
+	CacheDir() (string, error)
+}
+
+// CacheDir returns the Cache_Data directory of Chrome's simple cache. On
+// Windows Chrome uses its blockfile cache instead (data_0 to data_3 and
+// f_* files), which is reported as ErrUnsupportedCache.
+func (c *Chrome) CacheDir() (string, error) {
+	dbPath, err := c.DBPath()
+	if err != nil {
+		return "", err
+	}
+	dir, err := profileCacheDir(filepath.Dir(dbPath), "Cache", "Cache_Data")
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(filepath.Join(dir, "data_0")); err == nil {
+		return "", fmt.Errorf("%s: blockfile cache: %w", dir, ErrUnsupportedCache)
+	}
+	return dir, nil
+}
+
+// CacheDir returns the entries directory of Firefox's cache2.
+func (f *Firefox) CacheDir() (string, error) {
+	dbPath, err := f.DBPath()
+	if err != nil {
+		return "", err
+	}
+	return profileCacheDir(filepath.Dir(dbPath), "cache2", "entries")
+}
+
+// profileCacheDir finds the directory sub of a profile's disk cache. On
+// Linux and macOS browsers keep it under the user cache directory, at the
+// path the profile has under the user config directory, or under the home
+// directory without the leading dot (~/.mozilla becomes ~/.cache/mozilla);
+// on Windows Chrome keeps it in the profile and Firefox under LocalAppData.
+// Only the Linux locations are covered by tests.
+func profileCacheDir(profile string, sub ...string) (string, error) {
+	var candidates []string
+	if cache, err := os.UserCacheDir(); err == nil {
+		if config, err := os.UserConfigDir(); err == nil {
+			if rel, err := filepath.Rel(config, profile); err == nil && !strings.HasPrefix(rel, "..") {
+				candidates = append(candidates, filepath.Join(cache, rel))
+			}
+		}
+		if home, err := os.UserHomeDir(); err == nil {
+			if rel, err := filepath.Rel(home, profile); err == nil && strings.HasPrefix(rel, ".") && !strings.HasPrefix(rel, "..") {
+				candidates = append(candidates, filepath.Join(cache, rel[1:]))
+			}
+		}
+	}
+	candidates = append(candidates, profile)
+	for _, dir := range candidates {
+		dir = filepath.Join(append([]string{dir}, sub...)...)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("no disk cache found for profile %s: %w", profile, os.ErrNotExist)
+}
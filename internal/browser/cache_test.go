@@ -0,0 +1,64 @@
+package browser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestProfileCacheDir(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("cache locations are checked for Linux")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_CACHE_HOME", "")
+	mkdir := func(parts ...string) string {
+		dir := filepath.Join(append([]string{home}, parts...)...)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+
+	chrome := mkdir(".cache", "google-chrome", "Default", "Cache", "Cache_Data")
+	if got, err := profileCacheDir(filepath.Join(home, ".config", "google-chrome", "Default"), "Cache", "Cache_Data"); got != chrome {
+		t.Errorf("chrome cache = %q, %v; want %q", got, err, chrome)
+	}
+	firefox := mkdir(".cache", "mozilla", "firefox", "abc.default", "cache2", "entries")
+	if got, err := profileCacheDir(filepath.Join(home, ".mozilla", "firefox", "abc.default"), "cache2", "entries"); got != firefox {
+		t.Errorf("firefox cache = %q, %v; want %q", got, err, firefox)
+	}
+	// A cache kept in the profile itself, as on Windows.
+	profile := mkdir("profile")
+	inProfile := mkdir("profile", "Cache", "Cache_Data")
+	if got, err := profileCacheDir(profile, "Cache", "Cache_Data"); got != inProfile {
+		t.Errorf("cache in profile = %q, %v; want %q", got, err, inProfile)
+	}
+	if _, err := profileCacheDir(mkdir("empty"), "cache2", "entries"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing cache error = %v, want not exist", err)
+	}
+}
+
+func TestChromeBlockfileCache(t *testing.T) {
+	c := newTestChrome(t, chromeTestRows)
+	dbPath, _ := c.DBPath()
+	dir := filepath.Join(filepath.Dir(dbPath), "Cache", "Cache_Data")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.CacheDir(); err != nil || got != dir {
+		t.Errorf("CacheDir() = %q, %v; want %q", got, err, dir)
+	}
+	for _, name := range []string{"index", "data_0", "data_1", "f_000001"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.CacheDir(); !errors.Is(err, ErrUnsupportedCache) {
+		t.Errorf("CacheDir() of a blockfile cache error = %v, want ErrUnsupportedCache", err)
+	}
+}
@@ -3,10 +3,11 @@ package browser
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
+	"time"
 )
 
 type Chrome struct {
@@ -43,31 +44,79 @@ func (c *Chrome) DBPath() (string, error) {
 	return p, nil
 }
 
-const chromeListQuery = `
-	SELECT u.id, u.url, u.title, v.visit_time
+var chromeListQuery = listQuery{
+	selectFrom: `
+	SELECT u.id, v.id, u.url, u.title, v.visit_time, u.visit_count
 	FROM urls u
-	JOIN visits v ON v.url = u.id
-	ORDER BY v.visit_time DESC`
+	JOIN visits v ON v.url = u.id`,
+	visitTime:  "v.visit_time",
+	visitCount: "u.visit_count",
+	orderBy:    "v.visit_time DESC",
+	dbTime:     func(t time.Time) any { return TimeToChrome(t) },
+}
+
+var chromeTables = historyTables{
+	visits:     "visits",
+	visitItem:  "url",
+	items:      "urls",
+	visitCount: "visit_count",
+	related:    []string{"keyword_search_terms.url_id"},
+}
+
+// chromeTransitionLink is a link visit that starts and ends its redirect
+// chain (PAGE_TRANSITION_LINK | CHAIN_START | CHAIN_END).
+const chromeTransitionLink = 0x30000000
+
+var chromeImport = importSchema{
+	historyTables: chromeTables,
+	url:           "url",
+	title:         "title",
+	visitTime:     "visit_time",
+	lastVisit:     "last_visit_time",
+	dbTime:        TimeToChrome,
+	page: func(ctx context.Context, tx *sql.Tx, e HistoryEntry) (map[string]any, error) {
+		return map[string]any{
+			"url":             e.URL,
+			"title":           e.Title,
+			"visit_count":     0,
+			"typed_count":     0,
+			"hidden":          0,
+			"last_visit_time": TimeToChrome(e.VisitTime),
+		}, nil
+	},
+	visit: func(id int64, e HistoryEntry) map[string]any {
+		return map[string]any{
+			"url":            id,
+			"visit_time":     TimeToChrome(e.VisitTime),
+			"from_visit":     0,
+			"transition":     chromeTransitionLink,
+			"segment_id":     0,
+			"visit_duration": 0,
+		}
+	},
+}
 
 func chromeScanRow(name string) rowScanner {
 	return func(rows *sql.Rows) (HistoryEntry, bool, error) {
-		var id int64
+		var id, visitID int64
 		var url sql.NullString
 		var title sql.NullString
-		var visitTime sql.NullInt64
+		var visitTime, visitCount sql.NullInt64
 
-		if err := rows.Scan(&id, &url, &title, &visitTime); err != nil {
+		if err := rows.Scan(&id, &visitID, &url, &title, &visitTime, &visitCount); err != nil {
 			return HistoryEntry{}, false, err
 		}
 		if !url.Valid {
 			return HistoryEntry{}, false, nil
 		}
 		return HistoryEntry{
-			URL:       url.String,
-			Title:     title.String,
-			VisitTime: ChromeToTime(visitTime.Int64),
-			Browser:   name,
-			ItemID:    id,
+			URL:        url.String,
+			Title:      title.String,
+			VisitTime:  ChromeToTime(visitTime.Int64),
+			VisitCount: int(visitCount.Int64),
+			Browser:    name,
+			ItemID:     id,
+			VisitID:    visitID,
 		}, true, nil
 	}
 }
@@ -80,24 +129,101 @@ func (c *Chrome) List(ctx context.Context, opts ListOptions) ([]HistoryEntry, er
 	return listEntries(ctx, dbPath, c.name, chromeListQuery, chromeScanRow(c.name), opts)
 }
 
-func (c *Chrome) Delete(ctx context.Context, pattern *regexp.Regexp, dryRun bool) (DeleteResult, error) {
+func (c *Chrome) Delete(ctx context.Context, opts DeleteOptions) (DeleteResult, error) {
 	dbPath, err := c.DBPath()
 	if err != nil {
 		return DeleteResult{}, err
 	}
-	entries, err := c.List(ctx, ListOptions{Pattern: pattern})
+	entries := opts.Entries
+	if entries == nil {
+		entries, err = c.List(ctx, opts.ListOptions)
+		if err != nil {
+			return DeleteResult{}, err
+		}
+	}
+	bookmarks, err := c.Bookmarks(ctx)
 	if err != nil {
 		return DeleteResult{}, err
 	}
-	return deleteEntries(ctx, dbPath, c.name, entries, dryRun, func(ctx context.Context, tx *sql.Tx, e HistoryEntry) error {
-		if _, err := tx.ExecContext(ctx, "DELETE FROM visits WHERE url = ?", e.ItemID); err != nil {
-			return fmt.Errorf("delete visits: %w", err)
+	return deleteEntries(ctx, dbPath, c.name, entries, bookmarks, opts, chromeTables)
+}
+
+// Import adds entries as link visits.
+func (c *Chrome) Import(ctx context.Context, entries []HistoryEntry, dryRun bool) (ImportResult, error) {
+	dbPath, err := c.DBPath()
+	if err != nil {
+		return ImportResult{}, err
+	}
+	return importEntries(ctx, dbPath, c.name, entries, dryRun, chromeImport)
+}
+
+// chromeBookmarkNode is a node of the Bookmarks JSON tree.
+type chromeBookmarkNode struct {
+	Type     string               `json:"type"`
+	URL      string               `json:"url"`
+	Children []chromeBookmarkNode `json:"children"`
+}
+
+// Bookmarks reads the Bookmarks JSON file next to the History database.
+// A missing file means there are no bookmarks.
+func (c *Chrome) Bookmarks(ctx context.Context) (map[string]bool, error) {
+	dbPath, err := c.DBPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(dbPath), "Bookmarks"))
+	if os.IsNotExist(err) {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s bookmarks: %w", c.name, err)
+	}
+	var file struct {
+		Roots map[string]json.RawMessage `json:"roots"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s bookmarks: %w", c.name, err)
+	}
+	urls := make(map[string]bool)
+	var walk func(n chromeBookmarkNode)
+	walk = func(n chromeBookmarkNode) {
+		if n.Type == "url" && n.URL != "" {
+			urls[n.URL] = true
 		}
-		// keyword_search_terms may not exist in all versions
-		tx.ExecContext(ctx, "DELETE FROM keyword_search_terms WHERE url_id = ?", e.ItemID)
-		if _, err := tx.ExecContext(ctx, "DELETE FROM urls WHERE id = ?", e.ItemID); err != nil {
-			return fmt.Errorf("delete urls: %w", err)
+		for _, child := range n.Children {
+			walk(child)
 		}
-		return nil
-	})
+	}
+	for _, raw := range file.Roots {
+		// roots also holds non-node values such as sync_transaction_version
+		var root chromeBookmarkNode
+		if json.Unmarshal(raw, &root) == nil {
+			walk(root)
+		}
+	}
+	return urls, nil
+}
+
+// CookiesPath returns the Cookies database next to the History file. Newer
+// Chrome versions keep it under Network/.
+func (c *Chrome) CookiesPath() (string, error) {
+	dbPath, err := c.DBPath()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Dir(dbPath)
+	for _, p := range []string{filepath.Join(dir, "Network", "Cookies"), filepath.Join(dir, "Cookies")} {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("%s cookies not found in %s", c.name, dir)
+}
+
+func (c *Chrome) DeleteCookies(ctx context.Context, hosts []string, dryRun bool) (CookieResult, error) {
+	path, err := c.CookiesPath()
+	if err != nil {
+		return CookieResult{}, err
+	}
+	return deleteCookies(ctx, path, c.name, "cookies", "host_key", hosts, dryRun)
 }
@@ -2,8 +2,10 @@ package browser
 
 import (
 	"context"
+	"database/sql"
 	"regexp"
 	"testing"
+	"time"
 )
 
 func newTestChrome(t *testing.T, rows []chromeRow) *Chrome {
@@ -55,12 +57,63 @@ func TestChromeListPattern(t *testing.T) {
 	}
 }
 
+func TestChromeListDomain(t *testing.T) {
+	rows := append([]chromeRow{
+		{id: 4, url: strPtr("https://gist.github.com/odysa"), title: "Gist", visitTime: (1717290000 + 11644473600) * 1_000_000},
+		{id: 5, url: strPtr("https://search.example/?q=github.com"), title: "Search", visitTime: (1717290000 + 11644473600) * 1_000_000},
+	}, chromeTestRows...)
+	c := newTestChrome(t, rows)
+
+	entries, err := c.List(context.Background(), ListOptions{Domain: "github.com"})
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("List(domain) = %+v, want github.com and gist.github.com", entries)
+	}
+	for _, e := range entries {
+		if e.URL == "https://search.example/?q=github.com" {
+			t.Error("List(domain) matched the domain inside a query string")
+		}
+	}
+}
+
+func TestChromeListVisitsAndTime(t *testing.T) {
+	c := newTestChrome(t, chromeTestRows)
+	dbPath, _ := c.DBPath()
+	db, err := sql.Open("sqlite", "file:"+dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if _, err := db.Exec("UPDATE urls SET visit_count = 5 WHERE id = 3"); err != nil {
+		t.Fatalf("set visit_count: %v", err)
+	}
+	db.Close()
+	ctx := context.Background()
+
+	entries, err := c.List(ctx, ListOptions{MinVisits: 3})
+	if err != nil {
+		t.Fatalf("List(MinVisits) error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].URL != "https://github.com" || entries[0].VisitCount != 5 {
+		t.Errorf("List(MinVisits: 3) = %+v, want github.com with 5 visits", entries)
+	}
+
+	entries, err = c.List(ctx, ListOptions{Since: time.Unix(1717286400, 0)})
+	if err != nil {
+		t.Fatalf("List(Since) error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].URL != "https://golang.org" {
+		t.Errorf("List(Since) = %+v, want golang.org", entries)
+	}
+}
+
 func TestChromeDelete(t *testing.T) {
 	c := newTestChrome(t, chromeTestRows)
 	ctx := context.Background()
 
 	re := regexp.MustCompile(`example\.com`)
-	result, err := c.Delete(ctx, re, false)
+	result, err := c.Delete(ctx, DeleteOptions{ListOptions: ListOptions{Pattern: re}})
 	if err != nil {
 		t.Fatalf("Delete() error: %v", err)
 	}
@@ -107,3 +160,68 @@ func TestEdgeList(t *testing.T) {
 		}
 	}
 }
+
+type protectURL string
+
+func (p protectURL) Protects(e HistoryEntry) string {
+	if e.URL == string(p) {
+		return "test"
+	}
+	return ""
+}
+
+func TestChromeDeleteProtect(t *testing.T) {
+	c := newTestChrome(t, chromeTestRows)
+	ctx := context.Background()
+
+	result, err := c.Delete(ctx, DeleteOptions{Protect: protectURL("https://github.com")})
+	if err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if result.Deleted != 2 {
+		t.Errorf("Delete() Deleted = %d, want 2", result.Deleted)
+	}
+	if len(result.Protected) != 1 || result.Protected[0].Reason != "test" {
+		t.Fatalf("Delete() Protected = %+v, want github.com with reason %q", result.Protected, "test")
+	}
+
+	entries, _ := c.List(ctx, ListOptions{})
+	if len(entries) != 1 || entries[0].URL != "https://github.com" {
+		t.Errorf("after delete, List() = %+v, want only github.com", entries)
+	}
+}
+
+func TestChromeDeleteUntilKeepsRecentVisits(t *testing.T) {
+	c := newTestChrome(t, chromeTestRows)
+	dbPath, _ := c.DBPath()
+	db, err := sql.Open("sqlite", "file:"+dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	// A second, later visit to https://golang.org (2024-06-10).
+	if _, err := db.Exec("INSERT INTO visits (url, visit_time) VALUES (2, ?)", int64(1717977600+11644473600)*1_000_000); err != nil {
+		t.Fatalf("seed visit: %v", err)
+	}
+	db.Close()
+	ctx := context.Background()
+
+	until := time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC)
+	result, err := c.Delete(ctx, DeleteOptions{ListOptions: ListOptions{Until: until}})
+	if err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if result.Deleted != 3 {
+		t.Errorf("Delete() Deleted = %d, want 3", result.Deleted)
+	}
+
+	entries, _ := c.List(ctx, ListOptions{})
+	if len(entries) != 1 || entries[0].URL != "https://golang.org" {
+		t.Fatalf("after delete, List() = %+v, want the recent golang.org visit", entries)
+	}
+	if n := countRows(t, dbPath, "SELECT visit_count FROM urls WHERE id = 2"); n != 1 {
+		t.Errorf("urls.visit_count = %d, want 1", n)
+	}
+	if n := countRows(t, dbPath, "SELECT COUNT(*) FROM urls"); n != 1 {
+		t.Errorf("%d urls remain, want 1", n)
+	}
+}
@@ -0,0 +1,130 @@
+package browser
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/odysa/histctl/internal/domain"
+)
+
+// CookieResult summarizes a cookie cleanup.
+type CookieResult struct {
+	Matched int
+	Deleted int
+	ByHost  map[string]int // matched cookies per history host
+}
+
+// CookieCleaner is implemented by browsers whose cookie store can be edited.
+// Cookie values are never read, so encrypted Chrome cookies work as well.
+type CookieCleaner interface {
+	CookiesPath() (string, error)
+	DeleteCookies(ctx context.Context, hosts []string, dryRun bool) (CookieResult, error)
+}
+
+// Hosts returns the distinct lowercase hostnames of entries in first-seen order.
+func Hosts(entries []HistoryEntry) []string {
+	seen := make(map[string]bool)
+	var hosts []string
+	for _, e := range entries {
+		u, err := url.Parse(e.URL)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		h := strings.ToLower(u.Hostname())
+		if !seen[h] {
+			seen[h] = true
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// cookieHosts returns the cookie host values that are sent to host: host
+// itself, and host and each parent domain up to its registrable domain with
+// the leading dot of domain cookies. For www.example.com that is
+// www.example.com, .www.example.com and .example.com; an IP address has no
+// parents.
+func cookieHosts(host string) []string {
+	hosts := []string{host, "." + host}
+	top := domain.Registrable(host)
+	for h := host; h != top; {
+		_, parent, ok := strings.Cut(h, ".")
+		if !ok {
+			break
+		}
+		h = parent
+		hosts = append(hosts, "."+h)
+	}
+	return hosts
+}
+
+// deleteCookies removes rows of table whose hostCol is sent to one of hosts
+// (see cookieHosts), so cookies of parent domains such as .example.com go
+// with the history of www.example.com. Each row counts for the first host
+// it is sent to.
+func deleteCookies(ctx context.Context, dbPath, name, table, hostCol string, hosts []string, dryRun bool) (CookieResult, error) {
+	result := CookieResult{ByHost: make(map[string]int)}
+	if len(hosts) == 0 {
+		return result, nil
+	}
+
+	mode := "rw"
+	if dryRun {
+		mode = "ro"
+	}
+	db, err := sql.Open("sqlite", "file:"+dbPath+"?mode="+mode)
+	if err != nil {
+		return result, fmt.Errorf("open %s cookies db: %w", name, err)
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	seen := make(map[string]bool)
+	for _, h := range hosts {
+		var values []any
+		for _, v := range cookieHosts(h) {
+			if !seen[v] {
+				seen[v] = true
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		in := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+		var n int
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s IN (%s)", table, hostCol, in)
+		if err := tx.QueryRowContext(ctx, countQuery, values...).Scan(&n); err != nil {
+			return result, fmt.Errorf("count %s cookies: %w", name, err)
+		}
+		if n == 0 {
+			continue
+		}
+		result.ByHost[h] = n
+		result.Matched += n
+		if dryRun {
+			continue
+		}
+		deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)", table, hostCol, in)
+		if _, err := tx.ExecContext(ctx, deleteQuery, values...); err != nil {
+			return result, fmt.Errorf("delete %s cookies: %w", name, err)
+		}
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+	result.Deleted = result.Matched
+	return result, nil
+}
@@ -0,0 +1,159 @@
+package browser
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const chromeCookiesSchema = `
+CREATE TABLE cookies (
+	host_key TEXT,
+	name TEXT,
+	encrypted_value BLOB
+);`
+
+const firefoxCookiesSchema = `
+CREATE TABLE moz_cookies (
+	id INTEGER PRIMARY KEY,
+	host TEXT,
+	name TEXT,
+	value TEXT
+);`
+
+// seedCookies creates a cookie database at path and inserts one cookie per host.
+func seedCookies(t *testing.T, path, schema, table, hostCol string, hosts []string) {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatalf("open cookies db: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("create cookies schema: %v", err)
+	}
+	for _, h := range hosts {
+		if _, err := db.Exec("INSERT INTO "+table+" ("+hostCol+", name) VALUES (?, 'sid')", h); err != nil {
+			t.Fatalf("seed cookies: %v", err)
+		}
+	}
+}
+
+func countCookies(t *testing.T, path, table string) int {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatalf("open cookies db: %v", err)
+	}
+	defer db.Close()
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatalf("count cookies: %v", err)
+	}
+	return n
+}
+
+func TestHosts(t *testing.T) {
+	entries := []HistoryEntry{
+		{URL: "https://Example.com/a"},
+		{URL: "https://example.com/b"},
+		{URL: "https://www.golang.org/doc"},
+		{URL: "about:blank"},
+	}
+	got := Hosts(entries)
+	want := []string{"example.com", "www.golang.org"}
+	if len(got) != len(want) {
+		t.Fatalf("Hosts() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Hosts()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestChromeDeleteCookies(t *testing.T) {
+	c := newTestChrome(t, chromeTestRows)
+	dbPath, _ := c.DBPath()
+	cookiesPath := filepath.Join(filepath.Dir(dbPath), "Cookies")
+	seedCookies(t, cookiesPath, chromeCookiesSchema, "cookies", "host_key",
+		[]string{"example.com", ".example.com", ".golang.org", "notexample.com"})
+	ctx := context.Background()
+
+	result, err := c.DeleteCookies(ctx, []string{"example.com"}, true)
+	if err != nil {
+		t.Fatalf("DeleteCookies(dryRun) error: %v", err)
+	}
+	if result.Matched != 2 || result.Deleted != 0 {
+		t.Errorf("DeleteCookies(dryRun) = {Matched: %d, Deleted: %d}, want {2, 0}", result.Matched, result.Deleted)
+	}
+	if n := countCookies(t, cookiesPath, "cookies"); n != 4 {
+		t.Errorf("after dry run, %d cookies remain, want 4", n)
+	}
+
+	result, err = c.DeleteCookies(ctx, []string{"example.com"}, false)
+	if err != nil {
+		t.Fatalf("DeleteCookies() error: %v", err)
+	}
+	if result.Deleted != 2 || result.ByHost["example.com"] != 2 {
+		t.Errorf("DeleteCookies() = %+v, want 2 deleted for example.com", result)
+	}
+	if n := countCookies(t, cookiesPath, "cookies"); n != 2 {
+		t.Errorf("after delete, %d cookies remain, want 2", n)
+	}
+}
+
+func TestFirefoxDeleteCookies(t *testing.T) {
+	f := newTestFirefox(t, firefoxTestRows)
+	dbPath, _ := f.DBPath()
+	cookiesPath := filepath.Join(filepath.Dir(dbPath), "cookies.sqlite")
+	seedCookies(t, cookiesPath, firefoxCookiesSchema, "moz_cookies", "host",
+		[]string{".github.com", "github.com", "example.com"})
+	ctx := context.Background()
+
+	result, err := f.DeleteCookies(ctx, []string{"github.com"}, false)
+	if err != nil {
+		t.Fatalf("DeleteCookies() error: %v", err)
+	}
+	if result.Matched != 2 || result.Deleted != 2 {
+		t.Errorf("DeleteCookies() = {Matched: %d, Deleted: %d}, want {2, 2}", result.Matched, result.Deleted)
+	}
+	if n := countCookies(t, cookiesPath, "moz_cookies"); n != 1 {
+		t.Errorf("after delete, %d cookies remain, want 1", n)
+	}
+}
+
+func TestDeleteParentDomainCookies(t *testing.T) {
+	f := newTestFirefox(t, firefoxTestRows)
+	dbPath, _ := f.DBPath()
+	cookiesPath := filepath.Join(filepath.Dir(dbPath), "cookies.sqlite")
+	seedCookies(t, cookiesPath, firefoxCookiesSchema, "moz_cookies", "host",
+		[]string{"www.example.co.uk", ".example.co.uk", "example.co.uk", ".co.uk", "mail.example.co.uk"})
+
+	// .example.co.uk is sent to both hosts but counted once.
+	result, err := f.DeleteCookies(context.Background(), []string{"www.example.co.uk", "api.example.co.uk"}, true)
+	if err != nil {
+		t.Fatalf("DeleteCookies() error: %v", err)
+	}
+	if result.Matched != 2 || result.ByHost["www.example.co.uk"] != 2 || result.ByHost["api.example.co.uk"] != 0 {
+		t.Errorf("DeleteCookies() = %+v, want www.example.co.uk and .example.co.uk", result)
+	}
+}
+
+func TestCookieHostsIP(t *testing.T) {
+	for _, host := range []string{"192.168.1.1", "::1"} {
+		got := cookieHosts(host)
+		if want := []string{host, "." + host}; !slices.Equal(got, want) {
+			t.Errorf("cookieHosts(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestCookiesPathMissing(t *testing.T) {
+	c := newTestChrome(t, chromeTestRows)
+	if _, err := c.CookiesPath(); err == nil {
+		t.Error("CookiesPath() should fail when no Cookies db exists")
+	}
+}
@@ -0,0 +1,327 @@
+package browser
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/odysa/histctl/internal/session"
+	"howett.net/plist"
+)
+
+// ErrReadOnly is returned by Delete on backends that cannot be written.
+var ErrReadOnly = errors.New("read-only history export")
+
+// exportFormat reads one kind of history export.
+type exportFormat struct {
+	// member reports whether a file in an exported directory or zip
+	// archive is the history; the last match in path order is read.
+	member func(name string) bool
+	parse  func(data []byte) ([]HistoryEntry, error)
+}
+
+var exportFormats = map[string]exportFormat{
+	"takeout": {
+		member: func(name string) bool { return name == "BrowserHistory.json" || name == "History.json" },
+		parse:  parseTakeout,
+	},
+	"firefox-backup": {
+		member: func(name string) bool {
+			return strings.HasPrefix(name, "bookmarks-") && (strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".jsonlz4"))
+		},
+		parse: parseFirefoxBackup,
+	},
+	"safari-export": {
+		member: func(name string) bool { return name == "History.json" || name == "History.plist" },
+		parse:  parseSafariExport,
+	},
+}
+
+// ExportKinds returns the kinds of history export NewExportFile reads.
+func ExportKinds() []string {
+	kinds := make([]string, 0, len(exportFormats))
+	for kind := range exportFormats {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// ExportFile is a read-only Browser over history exported from a browser:
+// a Google Takeout BrowserHistory.json, a Firefox bookmarks backup or
+// Safari's exported History.json (or a legacy History.plist). The path may
+// also be the zip archive or directory the export came in.
+type ExportFile struct {
+	kind   string
+	path   string
+	format exportFormat
+}
+
+// NewExportFile returns the export of the given kind at path.
+func NewExportFile(kind, path string) (*ExportFile, error) {
+	format, ok := exportFormats[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown export %q; supported: %v", kind, ExportKinds())
+	}
+	if rest, ok := strings.CutPrefix(path, "~"+string(filepath.Separator)); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+	return &ExportFile{kind: kind, path: path, format: format}, nil
+}
+
+func (f *ExportFile) Name() string { return f.kind }
+
+func (f *ExportFile) DBPath() (string, error) {
+	if _, err := os.Stat(f.path); err != nil {
+		return "", err
+	}
+	return f.path, nil
+}
+
+// ProcessName is empty: no process holds an export open.
+func (f *ExportFile) ProcessName() string { return "" }
+
+// List reads the whole export and filters it in memory, newest first.
+func (f *ExportFile) List(ctx context.Context, opts ListOptions) ([]HistoryEntry, error) {
+	all, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	var entries []HistoryEntry
+	for _, e := range all {
+		if !opts.Matches(e) || !opts.inBounds(e) {
+			continue
+		}
+		entries = append(entries, e)
+		if opts.Limit > 0 && len(entries) >= opts.Limit {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// Delete always returns ErrReadOnly, for a dry run too, so callers that
+// preview before they back up never touch the export.
+func (f *ExportFile) Delete(ctx context.Context, opts DeleteOptions) (DeleteResult, error) {
+	return DeleteResult{}, ErrReadOnly
+}
+
+// read parses the export. Entries without a visit count get the number of
+// visits to their URL in the export.
+func (f *ExportFile) read() ([]HistoryEntry, error) {
+	data, err := readExport(f.path, f.format.member)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", f.kind, err)
+	}
+	parsed, err := f.format.parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s %s: %w", f.kind, f.path, err)
+	}
+
+	counts := make(map[string]int)
+	entries := parsed[:0]
+	for _, e := range parsed {
+		if e.URL == "" {
+			continue
+		}
+		counts[e.URL]++
+		entries = append(entries, e)
+	}
+	for i := range entries {
+		e := &entries[i]
+		e.Browser = f.kind
+		e.VisitID = int64(i + 1)
+		if e.VisitCount == 0 {
+			e.VisitCount = counts[e.URL]
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].VisitTime.After(entries[j].VisitTime)
+	})
+	return entries, nil
+}
+
+// readExport returns the contents of the export at path. A directory or
+// zip archive is searched for the file member accepts.
+func readExport(path string, member func(string) bool) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return readMember(os.DirFS(path), path, member)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return data, nil
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	return readMember(zr, path, member)
+}
+
+func readMember(fsys fs.FS, path string, member func(string) bool) ([]byte, error) {
+	var found string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && member(d.Name()) && name > found {
+			found = name
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == "" {
+		return nil, fmt.Errorf("no history file in %s", path)
+	}
+	return fs.ReadFile(fsys, found)
+}
+
+// parseTakeout reads the BrowserHistory.json of a Google Takeout export of
+// Chrome data.
+func parseTakeout(data []byte) ([]HistoryEntry, error) {
+	var export struct {
+		History []struct {
+			URL      string `json:"url"`
+			Title    string `json:"title"`
+			TimeUsec int64  `json:"time_usec"`
+		} `json:"Browser History"`
+	}
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, err
+	}
+	if export.History == nil {
+		return nil, fmt.Errorf(`no "Browser History" list`)
+	}
+	entries := make([]HistoryEntry, 0, len(export.History))
+	for _, h := range export.History {
+		entries = append(entries, HistoryEntry{URL: h.URL, Title: h.Title, VisitTime: time.UnixMicro(h.TimeUsec)})
+	}
+	return entries, nil
+}
+
+// firefoxBackupNode is a node of a Firefox bookmarks backup. Folders carry
+// Children, bookmarks carry URI; times are in microseconds.
+type firefoxBackupNode struct {
+	Title     string              `json:"title"`
+	URI       string              `json:"uri"`
+	DateAdded int64               `json:"dateAdded"`
+	Children  []firefoxBackupNode `json:"children"`
+}
+
+// parseFirefoxBackup reads a bookmarks backup, as JSON or compressed
+// .jsonlz4. Each bookmark becomes a visit at the time it was added.
+func parseFirefoxBackup(data []byte) ([]HistoryEntry, error) {
+	if bytes.HasPrefix(data, []byte("mozLz40")) {
+		var err error
+		if data, err = session.DecodeMozLz4(data); err != nil {
+			return nil, err
+		}
+	}
+	var root firefoxBackupNode
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	var entries []HistoryEntry
+	var walk func(n firefoxBackupNode)
+	walk = func(n firefoxBackupNode) {
+		// place: URIs are saved searches, not pages.
+		if n.URI != "" && !strings.HasPrefix(n.URI, "place:") {
+			entries = append(entries, HistoryEntry{URL: n.URI, Title: n.Title, VisitTime: FirefoxToTime(n.DateAdded)})
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+	return entries, nil
+}
+
+// parseSafariExport reads the History.json Safari writes with File > Export
+// Browsing Data, or the History.plist of Safari before version 8.
+func parseSafariExport(data []byte) ([]HistoryEntry, error) {
+	trimmed := bytes.TrimLeft(data, " \t\r\n\ufeff")
+	if len(trimmed) > 0 && trimmed[0] != '{' {
+		return parseSafariPlist(data)
+	}
+	var export struct {
+		Metadata struct {
+			DataType string `json:"data_type"`
+		} `json:"metadata"`
+		History []struct {
+			URL        string `json:"url"`
+			Title      string `json:"title"`
+			TimeUsec   int64  `json:"time_usec"`
+			VisitCount int    `json:"visit_count"`
+		} `json:"history"`
+	}
+	if err := json.Unmarshal(trimmed, &export); err != nil {
+		return nil, err
+	}
+	if export.History == nil && export.Metadata.DataType != "history" {
+		return nil, fmt.Errorf("not a Safari history export")
+	}
+	entries := make([]HistoryEntry, 0, len(export.History))
+	for _, h := range export.History {
+		entries = append(entries, HistoryEntry{
+			URL:        h.URL,
+			Title:      h.Title,
+			VisitTime:  time.UnixMicro(h.TimeUsec),
+			VisitCount: h.VisitCount,
+		})
+	}
+	return entries, nil
+}
+
+// parseSafariPlist reads a legacy History.plist. Each page keeps its URL
+// under the empty key and its last visit as a WebKit timestamp string.
+func parseSafariPlist(data []byte) ([]HistoryEntry, error) {
+	var history struct {
+		Dates []map[string]any `plist:"WebHistoryDates"`
+	}
+	if _, err := plist.Unmarshal(data, &history); err != nil {
+		return nil, err
+	}
+	if history.Dates == nil {
+		return nil, fmt.Errorf("no WebHistoryDates list")
+	}
+	entries := make([]HistoryEntry, 0, len(history.Dates))
+	for _, page := range history.Dates {
+		e := HistoryEntry{}
+		e.URL, _ = page[""].(string)
+		e.Title, _ = page["title"].(string)
+		if s, ok := page["lastVisitedDate"].(string); ok {
+			if ts, err := strconv.ParseFloat(s, 64); err == nil {
+				e.VisitTime = WebKitToTime(ts)
+			}
+		}
+		switch n := page["visitCount"].(type) {
+		case uint64:
+			e.VisitCount = int(n)
+		case int64:
+			e.VisitCount = int(n)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,175 @@
+package browser
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pierrec/lz4/v4"
+)
+
+const takeoutHistory = `{"Browser History": [
+	{"favicon_url": "https://go.dev/favicon.ico", "page_transition": "LINK", "title": "Go", "url": "https://go.dev/", "client_id": "x", "time_usec": 1700000000000000},
+	{"page_transition": "TYPED", "title": "Go", "url": "https://go.dev/", "client_id": "x", "time_usec": 1700000300000000},
+	{"page_transition": "LINK", "title": "Example", "url": "https://example.com/a", "client_id": "x", "time_usec": 1700000100000000}
+]}`
+
+func writeFile(t *testing.T, path string, data []byte) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTakeout(t *testing.T) {
+	dir := t.TempDir()
+	plain := writeFile(t, filepath.Join(dir, "BrowserHistory.json"), []byte(takeoutHistory))
+
+	// A Takeout archive keeps the file under Takeout/Chrome.
+	zipPath := filepath.Join(dir, "takeout.zip")
+	zf, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(zf)
+	w, err := zw.Create("Takeout/Chrome/BrowserHistory.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(takeoutHistory))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zf.Close()
+
+	ctx := context.Background()
+	for _, path := range []string{plain, zipPath, dir} {
+		b, err := Get("takeout:" + path)
+		if err != nil {
+			t.Fatalf("Get() error: %v", err)
+		}
+		entries, err := b.List(ctx, ListOptions{})
+		if err != nil {
+			t.Fatalf("List(%s) error: %v", path, err)
+		}
+		if len(entries) != 3 {
+			t.Fatalf("List(%s) = %d entries, want 3", path, len(entries))
+		}
+		first := entries[0]
+		if first.URL != "https://go.dev/" || !first.VisitTime.Equal(time.UnixMicro(1700000300000000)) ||
+			first.VisitCount != 2 || first.Browser != "takeout" {
+			t.Errorf("newest entry = %+v", first)
+		}
+	}
+
+	b, _ := Get("takeout:" + plain)
+	entries, err := b.List(ctx, ListOptions{Pattern: regexp.MustCompile("go"), Since: time.UnixMicro(1700000200000000)})
+	if err != nil || len(entries) != 1 {
+		t.Errorf("filtered List() = %v, %v; want the later go.dev visit", entries, err)
+	}
+	if _, err := b.Delete(ctx, DeleteOptions{DryRun: true}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Delete() error = %v, want ErrReadOnly", err)
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	if _, err := Get("netscape:history.json"); err == nil {
+		t.Error("Get() of an unknown export kind should fail")
+	}
+	path := writeFile(t, filepath.Join(t.TempDir(), "other.json"), []byte(`{"history": 1}`))
+	b, _ := Get("takeout:" + path)
+	if _, err := b.List(context.Background(), ListOptions{}); err == nil {
+		t.Error("List() of a file that is not a Takeout export should fail")
+	}
+}
+
+const firefoxBackup = `{"guid": "root________", "title": "", "type": "text/x-moz-place-container", "children": [
+	{"guid": "menu________", "title": "menu", "type": "text/x-moz-place-container", "children": [
+		{"guid": "a", "title": "Go", "type": "text/x-moz-place", "uri": "https://go.dev/", "dateAdded": 1700000000000000},
+		{"guid": "b", "title": "Recent", "type": "text/x-moz-place", "uri": "place:sort=8&maxResults=10", "dateAdded": 1700000000000000}
+	]},
+	{"guid": "toolbar_____", "title": "toolbar", "type": "text/x-moz-place-container", "children": [
+		{"guid": "c", "title": "Example", "type": "text/x-moz-place", "uri": "https://example.com/", "dateAdded": 1700000100000000}
+	]}
+]}`
+
+func TestFirefoxBackup(t *testing.T) {
+	// bookmarkbackups holds dated files; the newest, compressed, is read.
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bookmarks-2023-01-01_1_abc.json"), []byte(`{"children": []}`))
+	out := make([]byte, 12+lz4.CompressBlockBound(len(firefoxBackup)))
+	copy(out, "mozLz40\x00")
+	binary.LittleEndian.PutUint32(out[8:], uint32(len(firefoxBackup)))
+	var c lz4.Compressor
+	n, err := c.CompressBlock([]byte(firefoxBackup), out[12:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "bookmarks-2024-05-01_2_def.jsonlz4"), out[:12+n])
+
+	f, err := NewExportFile("firefox-backup", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := f.List(context.Background(), ListOptions{})
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("List() = %d entries, want 2 without the place: query", len(entries))
+	}
+	if entries[0].URL != "https://example.com/" || entries[0].Title != "Example" || entries[1].URL != "https://go.dev/" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestSafariExport(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Safari Export", "History.json"), []byte(`{
+		"metadata": {"browser_name": "Safari", "data_type": "history", "schema_version": 1},
+		"history": [
+			{"url": "https://go.dev/", "title": "Go", "time_usec": 1700000000000000, "visit_count": 7},
+			{"url": "https://example.com/", "time_usec": 1700000100000000}
+		]}`))
+	f, _ := NewExportFile("safari-export", dir)
+	entries, err := f.List(context.Background(), ListOptions{MinVisits: 2})
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].URL != "https://go.dev/" || entries[0].VisitCount != 7 {
+		t.Errorf("List(MinVisits: 2) = %+v", entries)
+	}
+
+	plistPath := writeFile(t, filepath.Join(dir, "History.plist"), []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0"><dict>
+	<key>WebHistoryDates</key>
+	<array>
+		<dict>
+			<key></key><string>https://old.example.com/</string>
+			<key>title</key><string>Old</string>
+			<key>lastVisitedDate</key><string>400000000.5</string>
+			<key>visitCount</key><integer>3</integer>
+		</dict>
+	</array>
+	<key>WebHistoryFileVersion</key><integer>1</integer>
+</dict></plist>`))
+	f, _ = NewExportFile("safari-export", plistPath)
+	entries, err = f.List(context.Background(), ListOptions{})
+	if err != nil {
+		t.Fatalf("List(plist) error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Title != "Old" || entries[0].VisitCount != 3 ||
+		!entries[0].VisitTime.Equal(WebKitToTime(400000000.5)) {
+		t.Errorf("List(plist) = %+v", entries)
+	}
+}
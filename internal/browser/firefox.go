@@ -2,11 +2,16 @@ package browser
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/base64"
 	"fmt"
+	"math/bits"
+	"net/url"
 	"os"
 	"path/filepath"
-	"regexp"
+	"strings"
+	"time"
 )
 
 type Firefox struct {
@@ -46,30 +51,152 @@ func (f *Firefox) DBPath() (string, error) {
 	return p, nil
 }
 
-const firefoxListQuery = `
-	SELECT p.id, p.url, p.title, v.visit_date
+var firefoxListQuery = listQuery{
+	selectFrom: `
+	SELECT p.id, v.id, p.url, p.title, v.visit_date, p.visit_count
 	FROM moz_places p
-	JOIN moz_historyvisits v ON v.place_id = p.id
-	ORDER BY v.visit_date DESC`
+	JOIN moz_historyvisits v ON v.place_id = p.id`,
+	visitTime:  "v.visit_date",
+	visitCount: "p.visit_count",
+	orderBy:    "v.visit_date DESC",
+	dbTime:     func(t time.Time) any { return TimeToFirefox(t) },
+}
+
+// moz_bookmarks.fk references moz_places.id, so bookmarked places are never
+// removed by deleteEntries.
+var firefoxTables = historyTables{
+	visits:     "moz_historyvisits",
+	visitItem:  "place_id",
+	items:      "moz_places",
+	visitCount: "visit_count",
+}
+
+// firefoxVisitLink is the visit_type of a followed link (TRANSITION_LINK).
+const firefoxVisitLink = 1
+
+var firefoxImport = importSchema{
+	historyTables: firefoxTables,
+	url:           "url",
+	title:         "title",
+	visitTime:     "visit_date",
+	lastVisit:     "last_visit_date",
+	dbTime:        TimeToFirefox,
+	page:          firefoxPage,
+	visit: func(id int64, e HistoryEntry) map[string]any {
+		return map[string]any{
+			"place_id":   id,
+			"visit_date": TimeToFirefox(e.VisitTime),
+			"visit_type": firefoxVisitLink,
+			"from_visit": 0,
+			"session":    0,
+			"source":     0,
+		}
+	},
+}
+
+// firefoxPage returns a moz_places row for e. Firefox fills in rev_host,
+// url_hash, guid and origin_id itself through temporary triggers, which
+// are not there when it is closed, so they are computed here. Frecency is
+// flagged for Firefox to recalculate.
+func firefoxPage(ctx context.Context, tx *sql.Tx, e HistoryEntry) (map[string]any, error) {
+	guid, err := firefoxGUID()
+	if err != nil {
+		return nil, err
+	}
+	page := map[string]any{
+		"url":                 e.URL,
+		"title":               sql.NullString{String: e.Title, Valid: e.Title != ""},
+		"rev_host":            ".",
+		"url_hash":            firefoxURLHash(e.URL),
+		"guid":                guid,
+		"visit_count":         0,
+		"hidden":              0,
+		"typed":               0,
+		"recalc_frecency":     1,
+		"recalc_alt_frecency": 1,
+		"last_visit_date":     TimeToFirefox(e.VisitTime),
+	}
+	u, err := url.Parse(e.URL)
+	if err != nil || u.Host == "" {
+		return page, nil
+	}
+	page["rev_host"] = reverse(strings.ToLower(u.Hostname())) + "."
+
+	var origins int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'moz_origins'").Scan(&origins); err != nil || origins == 0 {
+		return page, err
+	}
+	prefix, host := u.Scheme+"://", strings.ToLower(u.Host)
+	if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO moz_origins (prefix, host, frecency) VALUES (?, ?, 0)", prefix, host); err != nil {
+		return nil, fmt.Errorf("insert firefox origin: %w", err)
+	}
+	var originID int64
+	if err := tx.QueryRowContext(ctx, "SELECT id FROM moz_origins WHERE prefix = ? AND host = ?", prefix, host).Scan(&originID); err != nil {
+		return nil, fmt.Errorf("find firefox origin: %w", err)
+	}
+	page["origin_id"] = originID
+	return page, nil
+}
+
+// firefoxURLHash is the url_hash Firefox keeps for a URL, as computed by
+// the hash() SQL function of Places: the URL's mozilla::HashString, with
+// 16 bits of the scheme's hash above it so URLs can be looked up by scheme.
+func firefoxURLHash(rawURL string) int64 {
+	head := rawURL[:min(len(rawURL), 50)]
+	h := int64(mozHashString(rawURL))
+	if i := strings.IndexByte(head, ':'); i >= 0 {
+		h += int64(mozHashString(rawURL[:i])&0xFFFF) << 32
+	}
+	return h
+}
+
+// mozHashString is mozilla::HashString over the bytes of s.
+func mozHashString(s string) uint32 {
+	const goldenRatio = 0x9E3779B9
+	var h uint32
+	for i := 0; i < len(s); i++ {
+		h = goldenRatio * (bits.RotateLeft32(h, 5) ^ uint32(s[i]))
+	}
+	return h
+}
+
+// firefoxGUID returns a random 12-character Places GUID.
+func firefoxGUID() (string, error) {
+	b := make([]byte, 9)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func reverse(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
 
 func firefoxScanRow(rows *sql.Rows) (HistoryEntry, bool, error) {
-	var id int64
+	var id, visitID int64
 	var url sql.NullString
 	var title sql.NullString
-	var visitDate sql.NullInt64
+	var visitDate, visitCount sql.NullInt64
 
-	if err := rows.Scan(&id, &url, &title, &visitDate); err != nil {
+	if err := rows.Scan(&id, &visitID, &url, &title, &visitDate, &visitCount); err != nil {
 		return HistoryEntry{}, false, err
 	}
 	if !url.Valid {
 		return HistoryEntry{}, false, nil
 	}
 	return HistoryEntry{
-		URL:       url.String,
-		Title:     title.String,
-		VisitTime: FirefoxToTime(visitDate.Int64),
-		Browser:   "firefox",
-		ItemID:    id,
+		URL:        url.String,
+		Title:      title.String,
+		VisitTime:  FirefoxToTime(visitDate.Int64),
+		VisitCount: int(visitCount.Int64),
+		Browser:    "firefox",
+		ItemID:     id,
+		VisitID:    visitID,
 	}, true, nil
 }
 
@@ -81,22 +208,87 @@ func (f *Firefox) List(ctx context.Context, opts ListOptions) ([]HistoryEntry, e
 	return listEntries(ctx, dbPath, "firefox", firefoxListQuery, firefoxScanRow, opts)
 }
 
-func (f *Firefox) Delete(ctx context.Context, pattern *regexp.Regexp, dryRun bool) (DeleteResult, error) {
+func (f *Firefox) Delete(ctx context.Context, opts DeleteOptions) (DeleteResult, error) {
 	dbPath, err := f.DBPath()
 	if err != nil {
 		return DeleteResult{}, err
 	}
-	entries, err := f.List(ctx, ListOptions{Pattern: pattern})
+	entries := opts.Entries
+	if entries == nil {
+		entries, err = f.List(ctx, opts.ListOptions)
+		if err != nil {
+			return DeleteResult{}, err
+		}
+	}
+	bookmarks, err := f.Bookmarks(ctx)
 	if err != nil {
 		return DeleteResult{}, err
 	}
-	return deleteEntries(ctx, dbPath, "firefox", entries, dryRun, func(ctx context.Context, tx *sql.Tx, e HistoryEntry) error {
-		if _, err := tx.ExecContext(ctx, "DELETE FROM moz_historyvisits WHERE place_id = ?", e.ItemID); err != nil {
-			return fmt.Errorf("delete visits: %w", err)
+	return deleteEntries(ctx, dbPath, "firefox", entries, bookmarks, opts, firefoxTables)
+}
+
+// Import adds entries as link visits.
+func (f *Firefox) Import(ctx context.Context, entries []HistoryEntry, dryRun bool) (ImportResult, error) {
+	dbPath, err := f.DBPath()
+	if err != nil {
+		return ImportResult{}, err
+	}
+	return importEntries(ctx, dbPath, "firefox", entries, dryRun, firefoxImport)
+}
+
+const firefoxBookmarksQuery = `
+	SELECT DISTINCT p.url
+	FROM moz_bookmarks b
+	JOIN moz_places p ON p.id = b.fk`
+
+// Bookmarks returns the URLs referenced from moz_bookmarks.
+func (f *Firefox) Bookmarks(ctx context.Context) (map[string]bool, error) {
+	dbPath, err := f.DBPath()
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", "file:"+dbPath+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("open firefox db: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, firefoxBookmarksQuery)
+	if err != nil {
+		return nil, fmt.Errorf("query firefox bookmarks: %w", err)
+	}
+	defer rows.Close()
+
+	urls := make(map[string]bool)
+	for rows.Next() {
+		var url sql.NullString
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
 		}
-		if _, err := tx.ExecContext(ctx, "DELETE FROM moz_places WHERE id = ?", e.ItemID); err != nil {
-			return fmt.Errorf("delete places: %w", err)
+		if url.Valid {
+			urls[url.String] = true
 		}
-		return nil
-	})
+	}
+	return urls, rows.Err()
+}
+
+// CookiesPath returns cookies.sqlite from the same profile as places.sqlite.
+func (f *Firefox) CookiesPath() (string, error) {
+	dbPath, err := f.DBPath()
+	if err != nil {
+		return "", err
+	}
+	p := filepath.Join(filepath.Dir(dbPath), "cookies.sqlite")
+	if _, err := os.Stat(p); err != nil {
+		return "", fmt.Errorf("firefox cookies.sqlite not found: %w", err)
+	}
+	return p, nil
+}
+
+func (f *Firefox) DeleteCookies(ctx context.Context, hosts []string, dryRun bool) (CookieResult, error) {
+	path, err := f.CookiesPath()
+	if err != nil {
+		return CookieResult{}, err
+	}
+	return deleteCookies(ctx, path, "firefox", "moz_cookies", "host", hosts, dryRun)
 }
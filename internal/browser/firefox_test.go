@@ -60,7 +60,7 @@ func TestFirefoxDelete(t *testing.T) {
 	ctx := context.Background()
 
 	re := regexp.MustCompile(`example\.com`)
-	result, err := f.Delete(ctx, re, false)
+	result, err := f.Delete(ctx, DeleteOptions{ListOptions: ListOptions{Pattern: re}})
 	if err != nil {
 		t.Fatalf("Delete() error: %v", err)
 	}
@@ -0,0 +1,209 @@
+package browser
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ImportResult summarizes an import.
+type ImportResult struct {
+	Added   int // visits inserted (or that would be, on a dry run)
+	Skipped int // visits already in the database
+	Pages   int // pages created for URLs the database did not have
+}
+
+// Importer is implemented by browsers whose history can be added to.
+// Import inserts a visit per entry, creating pages for new URLs, and skips
+// visits to the same URL at the same time that are already there, so
+// importing a file twice adds nothing.
+type Importer interface {
+	Import(ctx context.Context, entries []HistoryEntry, dryRun bool) (ImportResult, error)
+}
+
+// importSchema is how a backend stores new pages and visits. Rows are
+// inserted with only the columns the database has, so one description
+// covers older and newer versions of a schema.
+type importSchema struct {
+	historyTables
+	url       string // items column with the URL
+	title     string // items column with the title
+	visitTime string // visits column with the visit time
+	lastVisit string // items column caching the latest visit time
+	dbTime    func(time.Time) int64
+	// page returns the columns of a new page row for e.
+	page func(ctx context.Context, tx *sql.Tx, e HistoryEntry) (map[string]any, error)
+	// visit returns the columns of a new visit row for e on page id.
+	visit func(id int64, e HistoryEntry) map[string]any
+}
+
+func importEntries(ctx context.Context, dbPath, name string, entries []HistoryEntry, dryRun bool, s importSchema) (ImportResult, error) {
+	var result ImportResult
+	mode := "rw"
+	if dryRun {
+		mode = "ro"
+	}
+	db, err := sql.Open("sqlite", "file:"+dbPath+"?mode="+mode)
+	if err != nil {
+		return result, fmt.Errorf("open %s db: %w", name, err)
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	itemCols, err := tableColumns(ctx, tx, s.items)
+	if err != nil {
+		return result, err
+	}
+	visitCols, err := tableColumns(ctx, tx, s.visits)
+	if err != nil {
+		return result, err
+	}
+
+	ids := make(map[string]int64) // URL to page id; 0 for pages a dry run would create
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT id, %s FROM %s WHERE %s IS NOT NULL", s.url, s.items, s.url))
+	if err != nil {
+		return result, fmt.Errorf("read %s pages: %w", name, err)
+	}
+	for rows.Next() {
+		var id int64
+		var url string
+		if err := rows.Scan(&id, &url); err != nil {
+			rows.Close()
+			return result, err
+		}
+		ids[url] = id
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	type visitKey struct {
+		url string
+		ts  int64
+	}
+	seen := make(map[visitKey]bool)
+	touched := make(map[int64]string) // page id to the latest imported title
+	visitExists := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = ? AND %s = ?", s.visits, s.visitItem, s.visitTime)
+	for _, e := range entries {
+		ts := s.dbTime(e.VisitTime)
+		key := visitKey{e.URL, ts}
+		if seen[key] {
+			result.Skipped++
+			continue
+		}
+		seen[key] = true
+
+		id, ok := ids[e.URL]
+		if ok && id != 0 {
+			var n int
+			if err := tx.QueryRowContext(ctx, visitExists, id, ts).Scan(&n); err != nil {
+				return result, fmt.Errorf("check %s visits: %w", name, err)
+			}
+			if n > 0 {
+				result.Skipped++
+				continue
+			}
+		}
+		result.Added++
+		if !ok {
+			result.Pages++
+		}
+		if dryRun {
+			ids[e.URL] = id
+			continue
+		}
+
+		if !ok {
+			page, err := s.page(ctx, tx, e)
+			if err != nil {
+				return result, err
+			}
+			if id, err = insertRow(ctx, tx, s.items, itemCols, page); err != nil {
+				return result, fmt.Errorf("insert %s page: %w", name, err)
+			}
+			ids[e.URL] = id
+		}
+		if _, err := insertRow(ctx, tx, s.visits, visitCols, s.visit(id, e)); err != nil {
+			return result, fmt.Errorf("insert %s visit: %w", name, err)
+		}
+		if t, ok := touched[id]; !ok || (t == "" && e.Title != "") {
+			touched[id] = e.Title
+		}
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	// Recount the cached visit count and latest visit of every page that
+	// got visits, and give untitled pages the imported title.
+	set := fmt.Sprintf("%s = (SELECT COUNT(*) FROM %s WHERE %s = %s.id)", s.visitCount, s.visits, s.visitItem, s.items)
+	if itemCols[s.lastVisit] {
+		set += fmt.Sprintf(", %s = (SELECT MAX(%s) FROM %s WHERE %s = %s.id)", s.lastVisit, s.visitTime, s.visits, s.visitItem, s.items)
+	}
+	update := fmt.Sprintf("UPDATE %s SET %s WHERE id = ?", s.items, set)
+	setTitle := fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ? AND (%s IS NULL OR %s = '')", s.items, s.title, s.title, s.title)
+	for id, title := range touched {
+		if _, err := tx.ExecContext(ctx, update, id); err != nil {
+			return result, fmt.Errorf("update %s %s: %w", name, s.visitCount, err)
+		}
+		if title != "" {
+			if _, err := tx.ExecContext(ctx, setTitle, title, id); err != nil {
+				return result, fmt.Errorf("update %s title: %w", name, err)
+			}
+		}
+	}
+	return result, tx.Commit()
+}
+
+// tableColumns returns the column names of table.
+func tableColumns(ctx context.Context, tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT name FROM pragma_table_info('%s')", table))
+	if err != nil {
+		return nil, fmt.Errorf("read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+	cols := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		cols[name] = true
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("table %s not found", table)
+	}
+	return cols, rows.Err()
+}
+
+// insertRow inserts values into table, leaving out columns it does not
+// have, and returns the new row id.
+func insertRow(ctx context.Context, tx *sql.Tx, table string, cols map[string]bool, values map[string]any) (int64, error) {
+	var names []string
+	for name := range values {
+		if cols[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	args := make([]any, len(names))
+	for i, name := range names {
+		args[i] = values[name]
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table,
+		strings.Join(names, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", "))
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
@@ -0,0 +1,197 @@
+package browser
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+// The import schemas add the columns Import fills in to the minimal ones.
+const chromeImportSchema = `
+CREATE TABLE urls (
+	id INTEGER PRIMARY KEY,
+	url LONGVARCHAR,
+	title LONGVARCHAR,
+	visit_count INTEGER DEFAULT 0 NOT NULL,
+	typed_count INTEGER DEFAULT 0 NOT NULL,
+	last_visit_time INTEGER NOT NULL,
+	hidden INTEGER DEFAULT 0 NOT NULL
+);
+CREATE TABLE visits (
+	id INTEGER PRIMARY KEY,
+	url INTEGER NOT NULL,
+	visit_time INTEGER NOT NULL,
+	from_visit INTEGER,
+	transition INTEGER DEFAULT 0 NOT NULL,
+	segment_id INTEGER,
+	visit_duration INTEGER DEFAULT 0 NOT NULL
+);`
+
+const firefoxImportSchema = `
+CREATE TABLE moz_origins (
+	id INTEGER PRIMARY KEY,
+	prefix TEXT NOT NULL,
+	host TEXT NOT NULL,
+	frecency INTEGER NOT NULL,
+	UNIQUE (prefix, host)
+);
+CREATE TABLE moz_places (
+	id INTEGER PRIMARY KEY,
+	url LONGVARCHAR,
+	title LONGVARCHAR,
+	rev_host LONGVARCHAR,
+	visit_count INTEGER DEFAULT 0,
+	hidden INTEGER DEFAULT 0 NOT NULL,
+	typed INTEGER DEFAULT 0 NOT NULL,
+	frecency INTEGER DEFAULT -1 NOT NULL,
+	last_visit_date INTEGER,
+	guid TEXT,
+	url_hash INTEGER DEFAULT 0 NOT NULL,
+	origin_id INTEGER REFERENCES moz_origins(id),
+	recalc_frecency INTEGER NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX moz_places_guid_uniqueindex ON moz_places (guid);
+CREATE TABLE moz_historyvisits (
+	id INTEGER PRIMARY KEY,
+	from_visit INTEGER,
+	place_id INTEGER,
+	visit_date INTEGER,
+	visit_type INTEGER,
+	session INTEGER
+);
+CREATE TABLE moz_bookmarks (
+	id INTEGER PRIMARY KEY,
+	type INTEGER,
+	fk INTEGER DEFAULT NULL,
+	title TEXT
+);`
+
+var importTestEntries = []HistoryEntry{
+	{URL: "https://Example.com:8443/a", Title: "A", VisitTime: time.UnixMicro(1_700_000_000_000_000)},
+	{URL: "https://Example.com:8443/a", Title: "A", VisitTime: time.UnixMicro(1_700_000_100_000_000)},
+	{URL: "https://golang.org", Title: "Go", VisitTime: time.UnixMicro(1_700_000_200_000_000)},
+	// A duplicate within the file.
+	{URL: "https://golang.org", Title: "Go", VisitTime: time.UnixMicro(1_700_000_200_000_000)},
+}
+
+func TestFirefoxImport(t *testing.T) {
+	path := createTestDB(t, firefoxImportSchema)
+	seed, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// golang.org is already there without a title, with one of the visits.
+	if _, err := seed.Exec(`INSERT INTO moz_places (id, url, guid, visit_count) VALUES (1, 'https://golang.org', 'existing0001', 1);
+		INSERT INTO moz_historyvisits (place_id, visit_date) VALUES (1, 1700000200000000)`); err != nil {
+		t.Fatal(err)
+	}
+	seed.Close()
+
+	entries := append(importTestEntries, HistoryEntry{URL: "https://golang.org", Title: "Go", VisitTime: time.UnixMicro(1_700_000_300_000_000)})
+	f := NewFirefox(path)
+	ctx := context.Background()
+	preview, err := f.Import(ctx, entries, true)
+	if err != nil {
+		t.Fatalf("Import(dry run) error: %v", err)
+	}
+	want := ImportResult{Added: 3, Skipped: 2, Pages: 1}
+	if preview != want {
+		t.Errorf("Import(dry run) = %+v, want %+v", preview, want)
+	}
+	if entries, _ := f.List(ctx, ListOptions{}); len(entries) != 1 {
+		t.Fatalf("dry run changed the database: %d entries", len(entries))
+	}
+
+	result, err := f.Import(ctx, entries, false)
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if result != want {
+		t.Errorf("Import() = %+v, want %+v", result, want)
+	}
+	again, err := f.Import(ctx, entries, false)
+	if err != nil || again.Added != 0 {
+		t.Errorf("second Import() = %+v, %v; want nothing added", again, err)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var (
+		count, lastVisit, urlHash, recalc int64
+		revHost, guid, originHost, title  string
+	)
+	err = db.QueryRow(`SELECT p.visit_count, p.last_visit_date, p.url_hash, p.recalc_frecency, p.rev_host, p.guid, o.host, p.title
+		FROM moz_places p JOIN moz_origins o ON o.id = p.origin_id WHERE p.url = 'https://Example.com:8443/a'`).
+		Scan(&count, &lastVisit, &urlHash, &recalc, &revHost, &guid, &originHost, &title)
+	if err != nil {
+		t.Fatalf("query imported page: %v", err)
+	}
+	if count != 2 || lastVisit != 1_700_000_100_000_000 || title != "A" {
+		t.Errorf("page visit_count %d last_visit_date %d title %q", count, lastVisit, title)
+	}
+	if revHost != "moc.elpmaxe." || originHost != "example.com:8443" || len(guid) != 12 || recalc != 1 {
+		t.Errorf("page rev_host %q origin %q guid %q recalc_frecency %d", revHost, originHost, guid, recalc)
+	}
+	if urlHash != firefoxURLHash("https://Example.com:8443/a") {
+		t.Errorf("url_hash = %d", urlHash)
+	}
+
+	if err := db.QueryRow("SELECT visit_count, title FROM moz_places WHERE id = 1").Scan(&count, &title); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || title != "Go" {
+		t.Errorf("existing page visit_count %d title %q, want 2 and the imported title", count, title)
+	}
+}
+
+func TestChromeImport(t *testing.T) {
+	path := createTestDB(t, chromeImportSchema)
+	c := NewChrome(path)
+	ctx := context.Background()
+	result, err := c.Import(ctx, importTestEntries, false)
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if want := (ImportResult{Added: 3, Skipped: 1, Pages: 2}); result != want {
+		t.Errorf("Import() = %+v, want %+v", result, want)
+	}
+
+	entries, err := c.List(ctx, ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("List() = %d entries, want 3", len(entries))
+	}
+	for _, e := range entries {
+		if e.URL == "https://Example.com:8443/a" && e.VisitCount != 2 {
+			t.Errorf("visit count = %d, want 2", e.VisitCount)
+		}
+	}
+	if !entries[len(entries)-1].VisitTime.Equal(importTestEntries[0].VisitTime) {
+		t.Errorf("oldest visit = %v, want %v", entries[len(entries)-1].VisitTime, importTestEntries[0].VisitTime)
+	}
+}
+
+func TestFirefoxURLHash(t *testing.T) {
+	// Places keeps the scheme's hash in bits 32-47; these are the prefixes
+	// of every http and https URL in a real places.sqlite.
+	for url, prefix := range map[string]int64{
+		"https://example.com/": 47356309405696,
+		"http://example.com/":  125507534323712,
+	} {
+		if got := firefoxURLHash(url); got>>32<<32 != prefix {
+			t.Errorf("firefoxURLHash(%q) = %d, want prefix %d", url, got, prefix)
+		}
+	}
+	if got := firefoxURLHash("https://example.com/"); got != 47357371248711 {
+		t.Errorf("firefoxURLHash() = %d", got)
+	}
+	if got := firefoxURLHash("no-scheme"); got != int64(mozHashString("no-scheme")) {
+		t.Errorf("firefoxURLHash(no scheme) = %d, want the plain hash", got)
+	}
+}
@@ -0,0 +1,108 @@
+package browser
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/odysa/histctl/internal/domain"
+)
+
+// Field is a part of a history entry that can be matched on its own.
+type Field string
+
+const (
+	FieldURL   Field = "url"
+	FieldTitle Field = "title"
+	FieldHost  Field = "host"
+	FieldPath  Field = "path"
+	FieldQuery Field = "query"
+)
+
+// Fields lists every matchable field.
+var Fields = []Field{FieldURL, FieldTitle, FieldHost, FieldPath, FieldQuery}
+
+// ParseField returns the field called name.
+func ParseField(name string) (Field, error) {
+	for _, f := range Fields {
+		if strings.EqualFold(name, string(f)) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown field %q; supported: %v", name, Fields)
+}
+
+// FieldMatch matches a regex against one field of an entry.
+type FieldMatch struct {
+	Field   Field
+	Pattern *regexp.Regexp
+}
+
+// NewFieldMatch compiles pattern as a case-insensitive regex for field.
+func NewFieldMatch(field Field, pattern string) (FieldMatch, error) {
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return FieldMatch{}, fmt.Errorf("invalid %s regex: %w", field, err)
+	}
+	return FieldMatch{Field: field, Pattern: re}, nil
+}
+
+// Value returns the text of field in e. Host, path and query come from the
+// parsed URL, with the path and query unescaped where possible.
+func (f Field) Value(e HistoryEntry) string {
+	switch f {
+	case FieldURL:
+		return e.URL
+	case FieldTitle:
+		return e.Title
+	}
+	u, err := url.Parse(e.URL)
+	if err != nil {
+		return ""
+	}
+	switch f {
+	case FieldHost:
+		return u.Hostname()
+	case FieldPath:
+		return u.Path
+	case FieldQuery:
+		if q, err := url.QueryUnescape(u.RawQuery); err == nil {
+			return q
+		}
+		return u.RawQuery
+	}
+	return ""
+}
+
+// Matches reports whether e passes the Pattern, Domain, Fields and Filter
+// filters. Since, Until, the visit bounds and Limit are applied by the
+// backend query and not considered.
+func (o ListOptions) Matches(e HistoryEntry) bool {
+	if o.Pattern != nil && !o.Pattern.MatchString(e.URL) {
+		return false
+	}
+	if o.Domain != "" && !domain.MatchURL(e.URL, o.Domain) {
+		return false
+	}
+	for _, m := range o.Fields {
+		if !m.Pattern.MatchString(m.Field.Value(e)) {
+			return false
+		}
+	}
+	return o.Filter == nil || o.Filter(e)
+}
+
+// inBounds reports whether e is within Since, Until and the visit bounds.
+func (o ListOptions) inBounds(e HistoryEntry) bool {
+	if !o.Since.IsZero() && e.VisitTime.Before(o.Since) {
+		return false
+	}
+	if !o.Until.IsZero() && e.VisitTime.After(o.Until) {
+		return false
+	}
+	if o.MinVisits > 0 && e.VisitCount < o.MinVisits {
+		return false
+	}
+	return o.MaxVisits <= 0 || e.VisitCount <= o.MaxVisits
+}
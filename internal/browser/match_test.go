@@ -0,0 +1,45 @@
+package browser
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+func TestFieldValue(t *testing.T) {
+	e := HistoryEntry{URL: "https://Mail.example.com:8443/inbox/a%20b?q=tax+return&x=1", Title: "Inbox"}
+	tests := []struct {
+		field Field
+		want  string
+	}{
+		{FieldURL, e.URL},
+		{FieldTitle, "Inbox"},
+		{FieldHost, "Mail.example.com"},
+		{FieldPath, "/inbox/a b"},
+		{FieldQuery, "q=tax return&x=1"},
+	}
+	for _, tt := range tests {
+		if got := tt.field.Value(e); got != tt.want {
+			t.Errorf("%s.Value() = %q, want %q", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestChromeListFields(t *testing.T) {
+	c := newTestChrome(t, chromeTestRows)
+	title, _ := NewFieldMatch(FieldTitle, "^git")
+	host, _ := NewFieldMatch(FieldHost, `\.com$`)
+
+	entries, err := c.List(context.Background(), ListOptions{Fields: []FieldMatch{title, host}})
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].URL != "https://github.com" {
+		t.Errorf("List(title, host) = %+v, want github.com", entries)
+	}
+
+	entries, _ = c.List(context.Background(), ListOptions{Pattern: regexp.MustCompile("example"), Fields: []FieldMatch{title}})
+	if len(entries) != 0 {
+		t.Errorf("List(pattern, title) = %+v, want none: all matchers must match", entries)
+	}
+}
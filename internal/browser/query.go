@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
@@ -12,17 +14,65 @@ import (
 // Returns false if the row should be skipped.
 type rowScanner func(*sql.Rows) (HistoryEntry, bool, error)
 
-// rowDeleter deletes a single entry within a transaction.
-type rowDeleter func(ctx context.Context, tx *sql.Tx, e HistoryEntry) error
+// listQuery is a backend's history query, split up so filters on the
+// visit time and visit count can be pushed into SQL. It must select the
+// page id, visit id, URL, title, visit time and visit count.
+type listQuery struct {
+	selectFrom string // SELECT ... FROM ... JOIN ...
+	visitTime  string // visit time column
+	visitCount string // page visit count column
+	orderBy    string
+	dbTime     func(time.Time) any // converts a time to visitTime's unit
+}
 
-func listEntries(ctx context.Context, dbPath, name, query string, scan rowScanner, opts ListOptions) ([]HistoryEntry, error) {
+// sql returns the query with WHERE conditions for opts and their arguments.
+func (q listQuery) sql(opts ListOptions) (string, []any) {
+	var conds []string
+	var args []any
+	if !opts.Since.IsZero() {
+		conds = append(conds, q.visitTime+" >= ?")
+		args = append(args, q.dbTime(opts.Since))
+	}
+	if !opts.Until.IsZero() {
+		conds = append(conds, q.visitTime+" <= ?")
+		args = append(args, q.dbTime(opts.Until))
+	}
+	if opts.MinVisits > 0 {
+		conds = append(conds, q.visitCount+" >= ?")
+		args = append(args, opts.MinVisits)
+	}
+	if opts.MaxVisits > 0 {
+		conds = append(conds, q.visitCount+" <= ?")
+		args = append(args, opts.MaxVisits)
+	}
+	s := q.selectFrom
+	if len(conds) > 0 {
+		s += "\n\tWHERE " + strings.Join(conds, " AND ")
+	}
+	return s + "\n\tORDER BY " + q.orderBy, args
+}
+
+// historyTables names the tables a backend keeps visits and pages in, so
+// deletes can be done per visit by shared code.
+type historyTables struct {
+	visits     string // visit table; rows are keyed by id
+	visitItem  string // visits column referencing the page
+	items      string // page table; rows are keyed by id
+	visitCount string // items column caching the number of visits
+	// related lists "table.column" pairs referencing a page that are removed
+	// along with it. Tables may be missing in some versions.
+	related []string
+}
+
+func listEntries(ctx context.Context, dbPath, name string, q listQuery, scan rowScanner, opts ListOptions) ([]HistoryEntry, error) {
 	db, err := sql.Open("sqlite", "file:"+dbPath+"?mode=ro")
 	if err != nil {
 		return nil, fmt.Errorf("open %s db: %w", name, err)
 	}
 	defer db.Close()
 
-	rows, err := db.QueryContext(ctx, query)
+	query, args := q.sql(opts)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query %s history: %w", name, err)
 	}
@@ -37,13 +87,7 @@ func listEntries(ctx context.Context, dbPath, name, query string, scan rowScanne
 		if !ok {
 			continue
 		}
-		if opts.Pattern != nil && !opts.Pattern.MatchString(entry.URL) {
-			continue
-		}
-		if !opts.Since.IsZero() && entry.VisitTime.Before(opts.Since) {
-			continue
-		}
-		if !opts.Until.IsZero() && entry.VisitTime.After(opts.Until) {
+		if !opts.Matches(entry) || !opts.inBounds(entry) {
 			continue
 		}
 		entries = append(entries, entry)
@@ -54,17 +98,41 @@ func listEntries(ctx context.Context, dbPath, name, query string, scan rowScanne
 	return entries, rows.Err()
 }
 
-func deleteEntries(ctx context.Context, dbPath, name string, entries []HistoryEntry, dryRun bool, del rowDeleter) (DeleteResult, error) {
-	result := DeleteResult{Matched: len(entries)}
-	if dryRun || len(entries) == 0 {
+// deleteEntries removes the visits behind entries. A page whose last visit
+// is removed goes with it, unless it is bookmarked; otherwise its cached
+// visit count is updated.
+func deleteEntries(ctx context.Context, dbPath, name string, entries []HistoryEntry, bookmarks map[string]bool, opts DeleteOptions, t historyTables) (DeleteResult, error) {
+	var result DeleteResult
+	for _, e := range entries {
+		if reason := protectReason(e, bookmarks, opts); reason != "" {
+			result.Protected = append(result.Protected, ProtectedEntry{HistoryEntry: e, Reason: reason})
+			continue
+		}
+		result.Entries = append(result.Entries, e)
+	}
+	result.Matched = len(result.Entries)
+	if opts.DryRun || len(result.Entries) == 0 {
 		return result, nil
 	}
 
-	db, err := sql.Open("sqlite", "file:"+dbPath+"?mode=rw")
+	dsn := "file:" + dbPath + "?mode=rw"
+	if opts.Secure {
+		// Zero freed pages instead of leaving deleted rows readable.
+		dsn += "&_pragma=secure_delete(1)"
+	}
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return result, fmt.Errorf("open %s db for writing: %w", name, err)
 	}
 	defer db.Close()
+	// Pragmas are per connection; keep everything on one.
+	db.SetMaxOpenConns(1)
+
+	if opts.Secure {
+		if err := useDeleteJournal(ctx, db); err != nil {
+			return result, fmt.Errorf("prepare %s secure delete: %w", name, err)
+		}
+	}
 
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
@@ -72,15 +140,70 @@ func deleteEntries(ctx context.Context, dbPath, name string, entries []HistoryEn
 	}
 	defer tx.Rollback()
 
-	for _, e := range entries {
-		if err := del(ctx, tx, e); err != nil {
-			return result, err
+	deleteVisit := fmt.Sprintf("DELETE FROM %s WHERE id = ?", t.visits)
+	var items []HistoryEntry
+	seen := make(map[int64]bool)
+	for _, e := range result.Entries {
+		if _, err := tx.ExecContext(ctx, deleteVisit, e.VisitID); err != nil {
+			return result, fmt.Errorf("delete visits: %w", err)
+		}
+		if !seen[e.ItemID] {
+			seen[e.ItemID] = true
+			items = append(items, e)
 		}
 	}
 
+	countVisits := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = ?", t.visits, t.visitItem)
+	updateCount := fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ?", t.items, t.visitCount)
+	deleteItem := fmt.Sprintf("DELETE FROM %s WHERE id = ?", t.items)
+	var removed []string
+	for _, e := range items {
+		var remaining int
+		if err := tx.QueryRowContext(ctx, countVisits, e.ItemID).Scan(&remaining); err != nil {
+			return result, fmt.Errorf("count visits: %w", err)
+		}
+		if remaining > 0 || bookmarks[e.URL] {
+			if _, err := tx.ExecContext(ctx, updateCount, remaining, e.ItemID); err != nil {
+				return result, fmt.Errorf("update %s: %w", t.visitCount, err)
+			}
+			continue
+		}
+		for _, rel := range t.related {
+			table, column, _ := strings.Cut(rel, ".")
+			tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s = ?", table, column), e.ItemID)
+		}
+		if _, err := tx.ExecContext(ctx, deleteItem, e.ItemID); err != nil {
+			return result, fmt.Errorf("delete %s: %w", t.items, err)
+		}
+		removed = append(removed, e.URL)
+	}
+
 	if err := tx.Commit(); err != nil {
 		return result, err
 	}
-	result.Deleted = len(entries)
+	result.Deleted = len(result.Entries)
+
+	if opts.Secure {
+		if err := compact(ctx, db); err != nil {
+			return result, fmt.Errorf("compact %s db: %w", name, err)
+		}
+		result.Residue, err = findResidue(dbPath, removed)
+		if err != nil {
+			return result, err
+		}
+	}
 	return result, nil
 }
+
+// protectReason returns why e must not be deleted, or "" if it may be.
+func protectReason(e HistoryEntry, bookmarks map[string]bool, opts DeleteOptions) string {
+	if opts.Protect != nil {
+		if reason := opts.Protect.Protects(e); reason != "" {
+			return reason
+		}
+	}
+	if bookmarks[e.URL] && !opts.IncludeBookmarked {
+		return "bookmarked"
+	}
+	return ""
+}
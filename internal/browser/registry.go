@@ -1,6 +1,9 @@
 package browser
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 var constructors = map[string]func() Browser{
 	"chrome":  func() Browser { return NewChrome("") },
@@ -8,8 +11,16 @@ var constructors = map[string]func() Browser{
 	"firefox": func() Browser { return NewFirefox("") },
 }
 
-// Get returns a Browser by name.
+// Get returns a Browser by name, or a read-only ExportFile for a name of
+// the form "kind:path" such as "takeout:BrowserHistory.json".
 func Get(name string) (Browser, error) {
+	if kind, path, ok := strings.Cut(name, ":"); ok {
+		f, err := NewExportFile(kind, path)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
 	if ctor, ok := constructors[name]; ok {
 		return ctor(), nil
 	}
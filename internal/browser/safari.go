@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
+	"time"
+
+	"howett.net/plist"
 )
 
 type Safari struct {
@@ -36,30 +38,45 @@ func (s *Safari) DBPath() (string, error) {
 	return p, nil
 }
 
-const safariListQuery = `
-	SELECT hi.id, hi.url, hv.title, hv.visit_time
+var safariListQuery = listQuery{
+	selectFrom: `
+	SELECT hi.id, hv.id, hi.url, hv.title, hv.visit_time, hi.visit_count
 	FROM history_items hi
-	JOIN history_visits hv ON hv.history_item = hi.id
-	ORDER BY hv.visit_time DESC`
+	JOIN history_visits hv ON hv.history_item = hi.id`,
+	visitTime:  "hv.visit_time",
+	visitCount: "hi.visit_count",
+	orderBy:    "hv.visit_time DESC",
+	dbTime:     func(t time.Time) any { return TimeToWebKit(t) },
+}
+
+var safariTables = historyTables{
+	visits:     "history_visits",
+	visitItem:  "history_item",
+	items:      "history_items",
+	visitCount: "visit_count",
+}
 
 func safariScanRow(rows *sql.Rows) (HistoryEntry, bool, error) {
-	var id int64
+	var id, visitID int64
 	var url sql.NullString
 	var title sql.NullString
 	var visitTime sql.NullFloat64
+	var visitCount sql.NullInt64
 
-	if err := rows.Scan(&id, &url, &title, &visitTime); err != nil {
+	if err := rows.Scan(&id, &visitID, &url, &title, &visitTime, &visitCount); err != nil {
 		return HistoryEntry{}, false, err
 	}
 	if !url.Valid {
 		return HistoryEntry{}, false, nil
 	}
 	return HistoryEntry{
-		URL:       url.String,
-		Title:     title.String,
-		VisitTime: WebKitToTime(visitTime.Float64),
-		Browser:   "safari",
-		ItemID:    id,
+		URL:        url.String,
+		Title:      title.String,
+		VisitTime:  WebKitToTime(visitTime.Float64),
+		VisitCount: int(visitCount.Int64),
+		Browser:    "safari",
+		ItemID:     id,
+		VisitID:    visitID,
 	}, true, nil
 }
 
@@ -75,22 +92,61 @@ func (s *Safari) List(ctx context.Context, opts ListOptions) ([]HistoryEntry, er
 	return entries, err
 }
 
-func (s *Safari) Delete(ctx context.Context, pattern *regexp.Regexp, dryRun bool) (DeleteResult, error) {
+func (s *Safari) Delete(ctx context.Context, opts DeleteOptions) (DeleteResult, error) {
 	dbPath, err := s.DBPath()
 	if err != nil {
 		return DeleteResult{}, err
 	}
-	entries, err := s.List(ctx, ListOptions{Pattern: pattern})
+	entries := opts.Entries
+	if entries == nil {
+		entries, err = s.List(ctx, opts.ListOptions)
+		if err != nil {
+			return DeleteResult{}, err
+		}
+	}
+	bookmarks, err := s.Bookmarks(ctx)
 	if err != nil {
 		return DeleteResult{}, err
 	}
-	return deleteEntries(ctx, dbPath, "safari", entries, dryRun, func(ctx context.Context, tx *sql.Tx, e HistoryEntry) error {
-		if _, err := tx.ExecContext(ctx, "DELETE FROM history_visits WHERE history_item = ?", e.ItemID); err != nil {
-			return fmt.Errorf("delete visits: %w", err)
+	return deleteEntries(ctx, dbPath, "safari", entries, bookmarks, opts, safariTables)
+}
+
+// safariBookmark is a node of Bookmarks.plist. Folders carry Children,
+// leaves carry URLString.
+type safariBookmark struct {
+	Type      string           `plist:"WebBookmarkType"`
+	URLString string           `plist:"URLString"`
+	Children  []safariBookmark `plist:"Children"`
+}
+
+// Bookmarks reads Bookmarks.plist next to History.db. A missing file means
+// there are no bookmarks.
+func (s *Safari) Bookmarks(ctx context.Context) (map[string]bool, error) {
+	dbPath, err := s.DBPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(dbPath), "Bookmarks.plist"))
+	if os.IsNotExist(err) {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read safari bookmarks: %w", err)
+	}
+	var root safariBookmark
+	if _, err := plist.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parse safari bookmarks: %w", err)
+	}
+	urls := make(map[string]bool)
+	var walk func(n safariBookmark)
+	walk = func(n safariBookmark) {
+		if n.Type == "WebBookmarkTypeLeaf" && n.URLString != "" {
+			urls[n.URLString] = true
 		}
-		if _, err := tx.ExecContext(ctx, "DELETE FROM history_items WHERE id = ?", e.ItemID); err != nil {
-			return fmt.Errorf("delete items: %w", err)
+		for _, child := range n.Children {
+			walk(child)
 		}
-		return nil
-	})
+	}
+	walk(root)
+	return urls, nil
 }
@@ -109,7 +109,7 @@ func TestSafariDelete(t *testing.T) {
 	ctx := context.Background()
 
 	re := regexp.MustCompile(`example\.com`)
-	result, err := s.Delete(ctx, re, false)
+	result, err := s.Delete(ctx, DeleteOptions{ListOptions: ListOptions{Pattern: re}})
 	if err != nil {
 		t.Fatalf("Delete() error: %v", err)
 	}
@@ -129,7 +129,7 @@ func TestSafariDeleteDryRun(t *testing.T) {
 	ctx := context.Background()
 
 	re := regexp.MustCompile(`example\.com`)
-	result, err := s.Delete(ctx, re, true)
+	result, err := s.Delete(ctx, DeleteOptions{ListOptions: ListOptions{Pattern: re}, DryRun: true})
 	if err != nil {
 		t.Fatalf("Delete(dryRun) error: %v", err)
 	}
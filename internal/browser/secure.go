@@ -0,0 +1,61 @@
+package browser
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// useDeleteJournal makes a rollback-journal database delete its journal
+// after each transaction, so old page images do not linger in it. WAL
+// databases are left alone: switching them out of WAL mode would persist.
+func useDeleteJournal(ctx context.Context, db *sql.DB) error {
+	var mode string
+	if err := db.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&mode); err != nil {
+		return err
+	}
+	if strings.EqualFold(mode, "wal") {
+		return nil
+	}
+	_, err := db.ExecContext(ctx, "PRAGMA journal_mode = DELETE")
+	return err
+}
+
+// compact flushes the WAL into the database, rebuilds the database without
+// free pages and truncates the WAL again.
+func compact(ctx context.Context, db *sql.DB) error {
+	for _, stmt := range []string{
+		"PRAGMA wal_checkpoint(TRUNCATE)",
+		"VACUUM",
+		"PRAGMA wal_checkpoint(TRUNCATE)",
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("%s: %w", stmt, err)
+		}
+	}
+	return nil
+}
+
+// findResidue scans the raw bytes of the database, its WAL and its journal
+// for any of urls.
+func findResidue(dbPath string, urls []string) ([]Residue, error) {
+	var found []Residue
+	for _, p := range []string{dbPath, dbPath + "-wal", dbPath + "-journal"} {
+		data, err := os.ReadFile(p)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return found, fmt.Errorf("scan %s: %w", p, err)
+		}
+		for _, u := range urls {
+			if bytes.Contains(data, []byte(u)) {
+				found = append(found, Residue{Path: p, URL: u})
+			}
+		}
+	}
+	return found, nil
+}
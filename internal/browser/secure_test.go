@@ -0,0 +1,51 @@
+package browser
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"regexp"
+	"testing"
+)
+
+func TestChromeDeleteSecure(t *testing.T) {
+	c := newTestChrome(t, chromeTestRows)
+	dbPath, _ := c.DBPath()
+	ctx := context.Background()
+
+	re := regexp.MustCompile(`example\.com`)
+	result, err := c.Delete(ctx, DeleteOptions{ListOptions: ListOptions{Pattern: re}, Secure: true})
+	if err != nil {
+		t.Fatalf("Delete(secure) error: %v", err)
+	}
+	if result.Deleted != 1 {
+		t.Errorf("Delete(secure) Deleted = %d, want 1", result.Deleted)
+	}
+	if len(result.Residue) != 0 {
+		t.Errorf("Delete(secure) Residue = %+v, want none", result.Residue)
+	}
+
+	data, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatalf("read db: %v", err)
+	}
+	if bytes.Contains(data, []byte("https://example.com")) {
+		t.Error("deleted URL is still present in the database file")
+	}
+	if !bytes.Contains(data, []byte("https://golang.org")) {
+		t.Error("surviving URL is missing from the database file")
+	}
+}
+
+func TestFindResidue(t *testing.T) {
+	c := newTestChrome(t, chromeTestRows)
+	dbPath, _ := c.DBPath()
+
+	found, err := findResidue(dbPath, []string{"https://github.com", "https://nowhere.example"})
+	if err != nil {
+		t.Fatalf("findResidue() error: %v", err)
+	}
+	if len(found) != 1 || found[0].URL != "https://github.com" || found[0].Path != dbPath {
+		t.Errorf("findResidue() = %+v, want github.com in %s", found, dbPath)
+	}
+}
@@ -10,7 +10,8 @@ import (
 const safariSchema = `
 CREATE TABLE history_items (
 	id INTEGER PRIMARY KEY,
-	url TEXT
+	url TEXT,
+	visit_count INTEGER DEFAULT 0
 );
 CREATE TABLE history_visits (
 	id INTEGER PRIMARY KEY,
@@ -36,12 +37,19 @@ const firefoxSchema = `
 CREATE TABLE moz_places (
 	id INTEGER PRIMARY KEY,
 	url TEXT,
-	title TEXT
+	title TEXT,
+	visit_count INTEGER DEFAULT 0
 );
 CREATE TABLE moz_historyvisits (
 	id INTEGER PRIMARY KEY,
 	place_id INTEGER REFERENCES moz_places(id),
 	visit_date INTEGER
+);
+CREATE TABLE moz_bookmarks (
+	id INTEGER PRIMARY KEY,
+	type INTEGER,
+	fk INTEGER DEFAULT NULL,
+	title TEXT
 );`
 
 // createTestDB creates a temporary SQLite database with the given schema
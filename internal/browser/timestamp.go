@@ -25,3 +25,18 @@ func ChromeToTime(ts int64) time.Time {
 func FirefoxToTime(ts int64) time.Time {
 	return time.UnixMicro(ts)
 }
+
+// TimeToWebKit converts t to a Safari/WebKit timestamp.
+func TimeToWebKit(t time.Time) float64 {
+	return float64(t.UnixNano())/1e9 - float64(webkitEpochOffset)
+}
+
+// TimeToChrome converts t to a Chrome/Edge timestamp.
+func TimeToChrome(t time.Time) int64 {
+	return t.UnixMicro() + chromeEpochOffset*1_000_000
+}
+
+// TimeToFirefox converts t to a Firefox timestamp.
+func TimeToFirefox(t time.Time) int64 {
+	return t.UnixMicro()
+}
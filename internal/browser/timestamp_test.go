@@ -92,3 +92,16 @@ func TestFirefoxToTime(t *testing.T) {
 		})
 	}
 }
+
+func TestTimeRoundTrip(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 6000, time.UTC)
+	if got := ChromeToTime(TimeToChrome(ts)); !got.Equal(ts) {
+		t.Errorf("ChromeToTime(TimeToChrome()) = %v, want %v", got, ts)
+	}
+	if got := FirefoxToTime(TimeToFirefox(ts)); !got.Equal(ts) {
+		t.Errorf("FirefoxToTime(TimeToFirefox()) = %v, want %v", got, ts)
+	}
+	if got := WebKitToTime(TimeToWebKit(ts)); got.Sub(ts).Abs() > time.Microsecond {
+		t.Errorf("WebKitToTime(TimeToWebKit()) = %v, want %v", got, ts)
+	}
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/domain"
+)
+
+// Config is the user's histctl configuration, read from config.toml.
+type Config struct {
+	Protect Protect `toml:"protect"`
+
+	patterns []*regexp.Regexp
+}
+
+// Protect lists history that no delete may remove. Patterns are
+// case-insensitive regexes matched against the URL; domains match the host
+// and all of its subdomains, and may not be public suffixes.
+type Protect struct {
+	Patterns []string `toml:"patterns"`
+	Domains  []string `toml:"domains"`
+}
+
+// Dir returns $XDG_CONFIG_HOME/histctl, falling back to the platform's user
+// config directory.
+func Dir() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		var err error
+		dir, err = os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, "histctl"), nil
+}
+
+// DefaultPath returns the config.toml inside Dir.
+func DefaultPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// Load reads the config file at path. An empty path means DefaultPath,
+// which yields an empty config if it does not exist; a path given
+// explicitly must exist, so a typo cannot turn protection off.
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		var err error
+		path, err = DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+	cfg := &Config{}
+	if _, err := toml.DecodeFile(path, cfg); err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !explicit {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("load config %s: %w", path, err)
+	}
+	if err := cfg.compile(); err != nil {
+		return nil, fmt.Errorf("load config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) compile() error {
+	for _, p := range c.Protect.Patterns {
+		re, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			return fmt.Errorf("invalid protect pattern %q: %w", p, err)
+		}
+		c.patterns = append(c.patterns, re)
+	}
+	for i, d := range c.Protect.Domains {
+		d, err := domain.Normalize(d)
+		if err != nil {
+			return fmt.Errorf("invalid protect domain: %w", err)
+		}
+		c.Protect.Domains[i] = d
+	}
+	return nil
+}
+
+// Protects implements browser.Protector.
+func (c *Config) Protects(e browser.HistoryEntry) string {
+	for i, re := range c.patterns {
+		if re.MatchString(e.URL) {
+			return "protect pattern " + c.Protect.Patterns[i]
+		}
+	}
+	for _, d := range c.Protect.Domains {
+		if domain.MatchURL(e.URL, d) {
+			return "protect domain " + d
+		}
+	}
+	return ""
+}
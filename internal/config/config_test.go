@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/odysa/histctl/internal/browser"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadMissing(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load() of a missing default config error: %v", err)
+	}
+	if got := cfg.Protects(browser.HistoryEntry{URL: "https://example.com"}); got != "" {
+		t.Errorf("empty config Protects() = %q, want \"\"", got)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "nope.toml")); err == nil {
+		t.Error("Load() of a missing explicit config should fail")
+	}
+}
+
+func TestLoadInvalidPattern(t *testing.T) {
+	path := writeConfig(t, "[protect]\npatterns = [\"(\"]\n")
+	if _, err := Load(path); err == nil {
+		t.Fatal("Load() should reject an invalid regex")
+	}
+}
+
+func TestDefaultPathXDG(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	path, err := DefaultPath()
+	if err != nil {
+		t.Fatalf("DefaultPath() error: %v", err)
+	}
+	if want := filepath.Join("/tmp/xdg", "histctl", "config.toml"); path != want {
+		t.Errorf("DefaultPath() = %q, want %q", path, want)
+	}
+}
+
+func TestProtects(t *testing.T) {
+	path := writeConfig(t, `
+[protect]
+patterns = ['wiki\.corp']
+domains = [".Tickets.example.com"]
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://WIKI.corp/page", `protect pattern wiki\.corp`},
+		{"https://tickets.example.com/T-1", "protect domain tickets.example.com"},
+		{"https://eu.tickets.example.com/T-2", "protect domain tickets.example.com"},
+		{"https://nottickets.example.com/", ""},
+		{"https://example.com/?next=tickets.example.com", ""},
+	}
+	for _, tt := range tests {
+		if got := cfg.Protects(browser.HistoryEntry{URL: tt.url}); got != tt.want {
+			t.Errorf("Protects(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestLoadRejectsPublicSuffix(t *testing.T) {
+	path := writeConfig(t, "[protect]\ndomains = [\"co.uk\"]\n")
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "public suffix") {
+		t.Errorf("Load() error = %v, want a public suffix error", err)
+	}
+}
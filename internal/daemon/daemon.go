@@ -0,0 +1,153 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/process"
+	"github.com/odysa/histctl/internal/rules"
+)
+
+// backupName names the one rolling backup the daemon keeps of each
+// database, <db>.daemon.bak, so retention rules do not pile up copies of
+// the history they expire.
+const backupName = "daemon"
+
+// Daemon applies cleanup rules on a schedule and whenever a browser exits.
+// It never prompts: running browsers are skipped and cleaned once they close.
+type Daemon struct {
+	Browsers []browser.Browser
+	Interval time.Duration // time between scheduled runs
+	Poll     time.Duration // how often browser processes are checked
+	NoBackup bool
+	Log      *log.Logger
+
+	// Load returns the current rules and protector. It is called before
+	// every run so edits to the rules or config files take effect.
+	Load func() ([]rules.Rule, browser.Protector, error)
+
+	isRunning func(name string) (bool, error)
+}
+
+// Run blocks until ctx is cancelled. It runs all rules once at start, then
+// every Interval, and for a single browser each time its process exits.
+// Interval and Poll must be positive.
+func (d *Daemon) Run(ctx context.Context) error {
+	if err := CheckIntervals(d.Interval, d.Poll); err != nil {
+		return err
+	}
+	if d.isRunning == nil {
+		d.isRunning = process.IsRunning
+	}
+	d.Log.Printf("started: %d browsers, every %s, polling every %s", len(d.Browsers), d.Interval, d.Poll)
+
+	running := d.runningState()
+	d.RunOnce(ctx, d.Browsers, "startup")
+
+	schedule := time.NewTicker(d.Interval)
+	defer schedule.Stop()
+	poll := time.NewTicker(d.Poll)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.Log.Printf("stopped")
+			return nil
+		case <-schedule.C:
+			d.RunOnce(ctx, d.Browsers, "schedule")
+		case <-poll.C:
+			now := d.runningState()
+			for _, b := range d.exited(running, now) {
+				d.RunOnce(ctx, []browser.Browser{b}, b.Name()+" exited")
+			}
+			running = now
+		}
+	}
+}
+
+// CheckIntervals returns an error unless the time between runs and between
+// process checks are both positive.
+func CheckIntervals(interval, poll time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %s", interval)
+	}
+	if poll <= 0 {
+		return fmt.Errorf("poll must be positive, got %s", poll)
+	}
+	return nil
+}
+
+// runningState reports which browsers are currently running. Browsers whose
+// state cannot be determined are left out.
+func (d *Daemon) runningState() map[string]bool {
+	state := make(map[string]bool)
+	for _, b := range d.Browsers {
+		running, err := d.isRunning(b.ProcessName())
+		if err != nil {
+			d.Log.Printf("could not check if %s is running: %v", b.Name(), err)
+			continue
+		}
+		state[b.Name()] = running
+	}
+	return state
+}
+
+// exited returns the browsers that were running before and are not now.
+func (d *Daemon) exited(before, now map[string]bool) []browser.Browser {
+	var out []browser.Browser
+	for _, b := range d.Browsers {
+		if before[b.Name()] && !now[b.Name()] {
+			if _, known := now[b.Name()]; known {
+				out = append(out, b)
+			}
+		}
+	}
+	return out
+}
+
+// RunOnce evaluates and applies the rules against browsers, logging the
+// plan and the outcome.
+func (d *Daemon) RunOnce(ctx context.Context, browsers []browser.Browser, reason string) {
+	rs, protect, err := d.Load()
+	if err != nil {
+		d.Log.Printf("run (%s): %v", reason, err)
+		return
+	}
+
+	plan := rules.Evaluate(ctx, rs, browsers, protect, time.Now())
+	for _, a := range plan.Actions {
+		if a.Err != nil {
+			d.Log.Printf("run (%s): rule %s: %s: %v", reason, a.Rule.Name, a.Browser.Name(), a.Err)
+		}
+	}
+	if plan.Matched() == 0 {
+		d.Log.Printf("run (%s): nothing to clean", reason)
+		return
+	}
+
+	for _, out := range rules.Apply(ctx, plan, rules.ApplyOptions{NoBackup: d.NoBackup, Rolling: backupName}) {
+		d.Log.Printf("run (%s): %s", reason, describe(out))
+	}
+}
+
+func describe(out rules.Outcome) string {
+	switch {
+	case out.Err != nil:
+		return fmt.Sprintf("%s: error: %v", out.Browser, out.Err)
+	case out.Skipped != "":
+		return fmt.Sprintf("%s: skipped (%s)", out.Browser, out.Skipped)
+	}
+	parts := []string{fmt.Sprintf("%s: deleted %d entries", out.Browser, out.Deleted)}
+	if out.Protected > 0 {
+		parts = append(parts, fmt.Sprintf("kept %d protected", out.Protected))
+	}
+	if out.Backup != "" {
+		parts = append(parts, "backup "+out.Backup)
+	}
+	return strings.Join(parts, ", ")
+}
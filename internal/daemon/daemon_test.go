@@ -0,0 +1,120 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/rules"
+)
+
+// fakeBrowser counts delete calls and never matches anything.
+type fakeBrowser struct {
+	name    string
+	deletes int
+}
+
+func (f *fakeBrowser) Name() string            { return f.name }
+func (f *fakeBrowser) DBPath() (string, error) { return "", nil }
+func (f *fakeBrowser) ProcessName() string     { return f.name }
+func (f *fakeBrowser) List(ctx context.Context, opts browser.ListOptions) ([]browser.HistoryEntry, error) {
+	return nil, nil
+}
+func (f *fakeBrowser) Delete(ctx context.Context, opts browser.DeleteOptions) (browser.DeleteResult, error) {
+	f.deletes++
+	return browser.DeleteResult{}, nil
+}
+
+func newTestDaemon(t *testing.T, browsers ...browser.Browser) (*Daemon, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	rs := []rules.Rule{{Name: "old", MaxAge: "30d"}}
+	return &Daemon{
+		Browsers: browsers,
+		Log:      log.New(&buf, "", 0),
+		Load: func() ([]rules.Rule, browser.Protector, error) {
+			return rs, nil, nil
+		},
+	}, &buf
+}
+
+func TestExited(t *testing.T) {
+	chrome := &fakeBrowser{name: "chrome"}
+	firefox := &fakeBrowser{name: "firefox"}
+	edge := &fakeBrowser{name: "edge"}
+	d, _ := newTestDaemon(t, chrome, firefox, edge)
+
+	before := map[string]bool{"chrome": true, "firefox": true, "edge": false}
+	now := map[string]bool{"chrome": false, "edge": false} // firefox unknown
+	got := d.exited(before, now)
+	if len(got) != 1 || got[0].Name() != "chrome" {
+		t.Errorf("exited() = %v, want [chrome]", got)
+	}
+}
+
+func TestRunningState(t *testing.T) {
+	chrome := &fakeBrowser{name: "chrome"}
+	d, _ := newTestDaemon(t, chrome)
+	d.isRunning = func(name string) (bool, error) { return name == "chrome", nil }
+	if state := d.runningState(); !state["chrome"] {
+		t.Errorf("runningState() = %v, want chrome running", state)
+	}
+}
+
+func TestRunOnceNothingToClean(t *testing.T) {
+	chrome := &fakeBrowser{name: "chrome"}
+	d, buf := newTestDaemon(t, chrome)
+
+	d.RunOnce(context.Background(), d.Browsers, "schedule")
+	if chrome.deletes != 1 {
+		t.Errorf("RunOnce() previewed %d times, want 1", chrome.deletes)
+	}
+	if !strings.Contains(buf.String(), "run (schedule): nothing to clean") {
+		t.Errorf("log = %q, want a nothing-to-clean line", buf.String())
+	}
+}
+
+func TestRunRejectsBadIntervals(t *testing.T) {
+	d, _ := newTestDaemon(t, &fakeBrowser{name: "chrome"})
+	for _, tt := range []struct{ interval, poll time.Duration }{
+		{0, time.Second},
+		{time.Hour, 0},
+		{-time.Hour, time.Second},
+	} {
+		d.Interval, d.Poll = tt.interval, tt.poll
+		if err := d.Run(context.Background()); err == nil {
+			t.Errorf("Run() with interval %s, poll %s should fail", tt.interval, tt.poll)
+		}
+	}
+}
+
+func TestUnit(t *testing.T) {
+	unit := Unit("/usr/local/bin/histctl", []string{"daemon", "--rules", "/home/me/my rules.toml"})
+	want := `ExecStart=/usr/local/bin/histctl daemon --rules "/home/me/my rules.toml"`
+	if !strings.Contains(unit, want) {
+		t.Errorf("Unit() = %q, want it to contain %q", unit, want)
+	}
+	unit = Unit("/opt/histctl", []string{"daemon", "--log=/home/me/100%$USER.log"})
+	if want := `ExecStart=/opt/histctl daemon --log=/home/me/100%%$$USER.log`; !strings.Contains(unit, want) {
+		t.Errorf("Unit() = %q, want it to contain %q", unit, want)
+	}
+	if !strings.Contains(unit, "WantedBy=default.target") {
+		t.Error("Unit() should be enabled by default.target")
+	}
+}
+
+func TestUnitPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	path, err := UnitPath()
+	if err != nil {
+		t.Fatalf("UnitPath() error: %v", err)
+	}
+	if want := filepath.Join("/tmp/xdg", "systemd", "user", "histctl.service"); path != want {
+		t.Errorf("UnitPath() = %q, want %q", path, want)
+	}
+}
@@ -0,0 +1,55 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UnitName is the file name of the generated systemd user unit.
+const UnitName = "histctl.service"
+
+// Unit renders a systemd user unit that runs exe with args.
+func Unit(exe string, args []string) string {
+	cmd := []string{quoteArg(exe)}
+	for _, a := range args {
+		cmd = append(cmd, quoteArg(a))
+	}
+	return fmt.Sprintf(`[Unit]
+Description=histctl browser history cleanup
+
+[Service]
+Type=simple
+ExecStart=%s
+Restart=on-failure
+RestartSec=30
+
+[Install]
+WantedBy=default.target
+`, strings.Join(cmd, " "))
+}
+
+// quoteArg quotes a command line argument for ExecStart if needed, and
+// escapes the % specifiers and $ variables systemd would expand.
+func quoteArg(s string) string {
+	s = strings.NewReplacer("%", "%%", "$", "$$").Replace(s)
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// UnitPath returns where systemd looks for user units:
+// $XDG_CONFIG_HOME/systemd/user/histctl.service.
+func UnitPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "systemd", "user", UnitName), nil
+}
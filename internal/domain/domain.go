@@ -0,0 +1,75 @@
+// Package domain matches hosts against a domain and its subdomains, using
+// the public suffix list compiled into the binary to refuse domains that
+// would match unrelated sites, such as "com" or "github.io".
+package domain
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Normalize returns d lowercased, in ASCII (punycode) form and without
+// leading or trailing dots. A scheme or path is stripped, so a pasted URL
+// works too. Public suffixes are rejected; hosts without a dot such as
+// "localhost" are allowed.
+func Normalize(d string) (string, error) {
+	in := d
+	d = strings.TrimSpace(d)
+	if strings.Contains(d, "://") {
+		u, err := url.Parse(d)
+		if err != nil {
+			return "", fmt.Errorf("invalid domain %q: %w", in, err)
+		}
+		d = u.Hostname()
+	}
+	d = strings.Trim(strings.ToLower(d), ".")
+	if d == "" || strings.ContainsAny(d, "/?#@: ") {
+		return "", fmt.Errorf("invalid domain %q", in)
+	}
+	d, err := idna.ToASCII(d)
+	if err != nil {
+		return "", fmt.Errorf("invalid domain %q: %w", in, err)
+	}
+
+	suffix, icann := publicsuffix.PublicSuffix(d)
+	if suffix == d && (icann || strings.Contains(d, ".")) {
+		return "", fmt.Errorf("%s is a public suffix; give a registrable domain such as example.%s", d, d)
+	}
+	return d, nil
+}
+
+// Match reports whether host is d or a subdomain of d. d must be
+// normalized; host is compared case-insensitively.
+func Match(host, d string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	return host == d || strings.HasSuffix(host, "."+d)
+}
+
+// MatchURL reports whether the host of rawURL is d or a subdomain of d.
+func MatchURL(rawURL, d string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return Match(u.Hostname(), d)
+}
+
+// Registrable returns the registrable part of host, such as "example.co.uk"
+// for "mail.example.co.uk", or host itself when it has none (an IP address,
+// "localhost" or a public suffix).
+func Registrable(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if net.ParseIP(host) != nil {
+		// Don't rely on the suffix list to reject the last octets as a domain.
+		return host
+	}
+	if d, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return d
+	}
+	return host
+}
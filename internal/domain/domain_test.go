@@ -0,0 +1,68 @@
+package domain
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{in: "example.com", want: "example.com"},
+		{in: " .Example.COM. ", want: "example.com"},
+		{in: "https://mail.example.co.uk/inbox", want: "mail.example.co.uk"},
+		{in: "bücher.de", want: "xn--bcher-kva.de"},
+		{in: "odysa.github.io", want: "odysa.github.io"},
+		{in: "localhost", want: "localhost"},
+		{in: "com", wantErr: true},
+		{in: "co.uk", wantErr: true},
+		{in: "github.io", wantErr: true},
+		{in: "example.com/path", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Normalize(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMatchURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/", true},
+		{"https://www.EXAMPLE.com:8443/a", true},
+		{"http://a.b.example.com", true},
+		{"https://notexample.com/", false},
+		{"https://search.test/?q=example.com", false},
+		{"https://example.com.evil.test/", false},
+		{"not a url", false},
+	}
+	for _, tt := range tests {
+		if got := MatchURL(tt.url, "example.com"); got != tt.want {
+			t.Errorf("MatchURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestRegistrable(t *testing.T) {
+	for host, want := range map[string]string{
+		"mail.Example.co.uk": "example.co.uk",
+		"odysa.github.io":    "odysa.github.io",
+		"www.github.com.":    "github.com",
+		"localhost":          "localhost",
+		"127.0.0.1":          "127.0.0.1",
+		"192.168.1.1":        "192.168.1.1",
+		"::1":                "::1",
+	} {
+		if got := Registrable(host); got != want {
+			t.Errorf("Registrable(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
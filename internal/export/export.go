@@ -0,0 +1,94 @@
+// Package export writes history entries in file formats for other tools:
+// CSV, NDJSON, a self-contained HTML report and Markdown tables. Entries
+// are written as they come, so large exports are not held in memory.
+package export
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/odysa/histctl/internal/browser"
+)
+
+// Writer writes entries in one format. Close finishes the document, such
+// as the end of an HTML report, and must be called even when no entries
+// were written; it does not close the underlying io.Writer.
+type Writer interface {
+	Write(e browser.HistoryEntry) error
+	Close() error
+}
+
+var constructors = map[string]func(io.Writer) Writer{
+	"csv":      newCSV,
+	"ndjson":   newNDJSON,
+	"html":     newHTML,
+	"markdown": newMarkdown,
+}
+
+var knownFormats = []string{"csv", "ndjson", "html", "markdown"}
+
+// extensions maps file extensions, and the short names accepted for
+// --format, to formats.
+var extensions = map[string]string{
+	"csv":      "csv",
+	"ndjson":   "ndjson",
+	"jsonl":    "ndjson",
+	"html":     "html",
+	"htm":      "html",
+	"md":       "markdown",
+	"markdown": "markdown",
+}
+
+// Register adds a format, or replaces one, under name and the given file
+// extensions.
+func Register(name string, ctor func(io.Writer) Writer, exts ...string) {
+	if _, ok := constructors[name]; !ok {
+		knownFormats = append(knownFormats, name)
+	}
+	constructors[name] = ctor
+	extensions[name] = name
+	for _, ext := range exts {
+		extensions[strings.TrimPrefix(ext, ".")] = name
+	}
+}
+
+// New returns a Writer for format, which is a format name or file
+// extension such as "md".
+func New(format string, w io.Writer) (Writer, error) {
+	ctor, err := Lookup(format)
+	if err != nil {
+		return nil, err
+	}
+	return ctor(w), nil
+}
+
+// Lookup returns the constructor of format, as New accepts it.
+func Lookup(format string) (func(io.Writer) Writer, error) {
+	if name, ok := extensions[strings.ToLower(format)]; ok {
+		return constructors[name], nil
+	}
+	return nil, fmt.Errorf("unknown format %q; supported: %v", format, knownFormats)
+}
+
+// ForPath returns the format for a file name by its extension, or "" if
+// the extension is not known.
+func ForPath(path string) string {
+	return extensions[strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))]
+}
+
+// Formats returns the names of the supported formats.
+func Formats() []string {
+	return append([]string{}, knownFormats...)
+}
+
+// WriteAll writes entries with w and closes it.
+func WriteAll(w Writer, entries []browser.HistoryEntry) error {
+	for _, e := range entries {
+		if err := w.Write(e); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
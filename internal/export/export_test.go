@@ -0,0 +1,146 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+)
+
+var testEntries = []browser.HistoryEntry{
+	{URL: "https://example.com/a?x=1,2", Title: `Say "hi", | [there]`, VisitTime: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), VisitCount: 3, Browser: "chrome"},
+	{URL: "javascript:alert(1)", Title: "<script>", VisitTime: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Browser: "firefox"},
+}
+
+func export(t *testing.T, format string, entries []browser.HistoryEntry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := New(format, &buf)
+	if err != nil {
+		t.Fatalf("New(%q): %v", format, err)
+	}
+	if err := WriteAll(w, entries); err != nil {
+		t.Fatalf("WriteAll(%q): %v", format, err)
+	}
+	return buf.String()
+}
+
+func TestCSV(t *testing.T) {
+	records, err := csv.NewReader(strings.NewReader(export(t, "csv", testEntries))).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+	if len(records) != 3 || records[0][0] != "visit_time" {
+		t.Fatalf("CSV = %q, want a header and 2 records", records)
+	}
+	want := []string{"2026-01-02T03:04:05Z", "chrome", `Say "hi", | [there]`, "https://example.com/a?x=1,2", "3"}
+	for i := range want {
+		if records[1][i] != want[i] {
+			t.Errorf("CSV record field %d = %q, want %q", i, records[1][i], want[i])
+		}
+	}
+	if got := export(t, "csv", nil); got != "visit_time,browser,title,url,visit_count\n" {
+		t.Errorf("empty CSV = %q, want the header", got)
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(export(t, "jsonl", testEntries)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("NDJSON has %d lines, want 2", len(lines))
+	}
+	var e browser.HistoryEntry
+	if err := json.Unmarshal([]byte(lines[0]), &e); err != nil || e.Title != testEntries[0].Title {
+		t.Errorf("NDJSON line = %s (%v)", lines[0], err)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(export(t, "md", testEntries)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Markdown has %d lines, want 4", len(lines))
+	}
+	if !strings.Contains(lines[2], `[Say "hi", \| \[there\]](<https://example.com/a?x=1,2>)`) {
+		t.Errorf("Markdown row = %q", lines[2])
+	}
+	if !strings.Contains(lines[3], "&lt;script&gt;") {
+		t.Errorf("Markdown row = %q, want the tag escaped", lines[3])
+	}
+}
+
+func TestHTML(t *testing.T) {
+	out := export(t, "html", testEntries)
+	for _, want := range []string{"<!DOCTYPE html>", "Say &#34;hi&#34;, | [there]", `href="https://example.com/a?x=1,2"`, "&lt;script&gt;", "2 visits", "</html>"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML missing %q", want)
+		}
+	}
+	if strings.Contains(out, `href="javascript:`) {
+		t.Error("HTML links a javascript: URL")
+	}
+	if empty := export(t, "html", nil); !strings.Contains(empty, "<table>") || !strings.Contains(empty, "</html>") {
+		t.Errorf("empty HTML is not a complete page: %s", empty)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New("xml", &bytes.Buffer{}); err == nil {
+		t.Error("New(xml) should fail")
+	}
+	for path, want := range map[string]string{"out.CSV": "csv", "a.jsonl": "ndjson", "r.htm": "html", "t.md": "markdown", "x.txt": ""} {
+		if got := ForPath(path); got != want {
+			t.Errorf("ForPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("t").Parse("{{.Browser}} {{.URL}}\n"))
+	var buf bytes.Buffer
+	if err := WriteAll(Template(tmpl)(&buf), testEntries); err != nil {
+		t.Fatal(err)
+	}
+	if want := "chrome https://example.com/a?x=1,2\nfirefox javascript:alert(1)\n"; buf.String() != want {
+		t.Errorf("Template() wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	for _, format := range []string{"csv", "ndjson"} {
+		path := t.TempDir() + "/history." + format
+		if err := os.WriteFile(path, []byte(export(t, format, testEntries)), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%s): %v", format, err)
+		}
+		if len(got) != len(testEntries) {
+			t.Fatalf("ReadFile(%s) = %d entries, want %d", format, len(got), len(testEntries))
+		}
+		for i, e := range got {
+			want := testEntries[i]
+			if e.URL != want.URL || e.Title != want.Title || !e.VisitTime.Equal(want.VisitTime) || e.VisitCount != want.VisitCount || e.Browser != want.Browser {
+				t.Errorf("ReadFile(%s)[%d] = %+v, want %+v", format, i, e, want)
+			}
+		}
+	}
+
+	path := t.TempDir() + "/list.json"
+	data, _ := json.Marshal(testEntries)
+	os.WriteFile(path, data, 0o600)
+	if got, err := ReadFile(path); err != nil || len(got) != 2 {
+		t.Errorf("ReadFile(json array) = %d entries, %v", len(got), err)
+	}
+
+	os.WriteFile(path, []byte("url,title\nhttps://x.com,X\n"), 0o600)
+	if _, err := ReadFile(path); err == nil {
+		t.Error("ReadFile(CSV without visit_time) should fail")
+	}
+}
@@ -0,0 +1,134 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+)
+
+// csvWriter writes a header row and one RFC 4180 record per visit. Times
+// are RFC 3339 with all the precision the browser keeps, so imports can
+// match them to existing visits.
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func newCSV(w io.Writer) Writer { return &csvWriter{w: csv.NewWriter(w)} }
+
+func (c *csvWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+	return c.w.Write([]string{"visit_time", "browser", "title", "url", "visit_count"})
+}
+
+func (c *csvWriter) Write(e browser.HistoryEntry) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	return c.w.Write([]string{
+		e.VisitTime.Format(time.RFC3339Nano),
+		e.Browser,
+		e.Title,
+		e.URL,
+		strconv.Itoa(e.VisitCount),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ndjsonWriter writes one JSON object per line, as list --json does for
+// the whole array.
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func newNDJSON(w io.Writer) Writer { return ndjsonWriter{enc: json.NewEncoder(w)} }
+
+func (n ndjsonWriter) Write(e browser.HistoryEntry) error { return n.enc.Encode(e) }
+
+func (n ndjsonWriter) Close() error { return nil }
+
+// markdownWriter writes a GitHub-flavored Markdown table with titles
+// linked to their URLs.
+type markdownWriter struct {
+	w      io.Writer
+	header bool
+	err    error
+}
+
+func newMarkdown(w io.Writer) Writer { return &markdownWriter{w: w} }
+
+func (m *markdownWriter) printf(format string, args ...any) {
+	if m.err == nil {
+		_, m.err = fmt.Fprintf(m.w, format, args...)
+	}
+}
+
+func (m *markdownWriter) writeHeader() {
+	if !m.header {
+		m.header = true
+		m.printf("| Time | Browser | Title | Visits |\n|------|---------|-------|-------:|\n")
+	}
+}
+
+func (m *markdownWriter) Write(e browser.HistoryEntry) error {
+	m.writeHeader()
+	title := e.Title
+	if title == "" {
+		title = e.URL
+	}
+	m.printf("| %s | %s | [%s](<%s>) | %d |\n",
+		e.VisitTime.Local().Format("2006-01-02 15:04"), mdEscape(e.Browser),
+		mdEscape(title), mdURL(e.URL), e.VisitCount)
+	return m.err
+}
+
+func (m *markdownWriter) Close() error {
+	m.writeHeader()
+	return m.err
+}
+
+var mdReplacer = strings.NewReplacer(
+	`\`, `\\`, "|", `\|`, "[", `\[`, "]", `\]`, "*", `\*`, "_", `\_`, "`", "\\`",
+	"<", "&lt;", ">", "&gt;", "\r", " ", "\n", " ",
+)
+
+// mdEscape makes s safe as the text of a table cell.
+func mdEscape(s string) string { return mdReplacer.Replace(s) }
+
+var mdURLReplacer = strings.NewReplacer("|", "%7C", "<", "%3C", ">", "%3E", " ", "%20", "\n", "", "\r", "")
+
+// mdURL makes u safe as a <...> link destination in a table cell.
+func mdURL(u string) string { return mdURLReplacer.Replace(u) }
+
+// templateWriter executes a text/template once per entry.
+type templateWriter struct {
+	tmpl *template.Template
+	w    io.Writer
+}
+
+// Template returns a format that executes tmpl with each HistoryEntry, for
+// line formats of the caller's choosing. tmpl supplies its own newlines.
+func Template(tmpl *template.Template) func(io.Writer) Writer {
+	return func(w io.Writer) Writer { return templateWriter{tmpl: tmpl, w: w} }
+}
+
+func (t templateWriter) Write(e browser.HistoryEntry) error { return t.tmpl.Execute(t.w, e) }
+
+func (t templateWriter) Close() error { return nil }
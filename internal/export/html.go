@@ -0,0 +1,86 @@
+package export
+
+import (
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+)
+
+// htmlTemplate is a self-contained report: styles are inline and nothing
+// is loaded from the network. html/template escapes titles and drops
+// unsafe link schemes such as javascript:.
+var htmlTemplate = template.Must(template.New("report").Parse(`
+{{- define "head" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Browser history</title>
+<style>
+body { font: 14px/1.4 -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.4em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: .35em .6em; border-bottom: 1px solid #e4e4e4; vertical-align: top; }
+th { background: #f4f2fd; }
+td.time, td.visits { white-space: nowrap; }
+td.visits { text-align: right; }
+td.url { color: #777; word-break: break-all; font-size: .9em; }
+a { color: #5b3ccf; text-decoration: none; }
+a:hover { text-decoration: underline; }
+footer { margin-top: 1em; color: #777; }
+</style>
+</head>
+<body>
+<h1>Browser history</h1>
+<p>Exported by histctl on {{.}}.</p>
+<table>
+<thead><tr><th>Time</th><th>Browser</th><th>Title</th><th>URL</th><th>Visits</th></tr></thead>
+<tbody>
+{{end -}}
+{{- define "row" -}}
+<tr><td class="time">{{.VisitTime.Local.Format "2006-01-02 15:04"}}</td><td>{{.Browser}}</td><td><a href="{{.URL}}">{{if .Title}}{{.Title}}{{else}}{{.URL}}{{end}}</a></td><td class="url">{{.URL}}</td><td class="visits">{{.VisitCount}}</td></tr>
+{{end -}}
+{{- define "foot" -}}
+</tbody>
+</table>
+<footer>{{.}} visits</footer>
+</body>
+</html>
+{{end -}}
+`))
+
+// htmlWriter writes the report head before the first row, so an empty
+// export is still a complete page.
+type htmlWriter struct {
+	w     io.Writer
+	head  bool
+	count int
+}
+
+func newHTML(w io.Writer) Writer { return &htmlWriter{w: w} }
+
+func (h *htmlWriter) writeHead() error {
+	if h.head {
+		return nil
+	}
+	h.head = true
+	return htmlTemplate.ExecuteTemplate(h.w, "head", time.Now().Format("2006-01-02 15:04"))
+}
+
+func (h *htmlWriter) Write(e browser.HistoryEntry) error {
+	if err := h.writeHead(); err != nil {
+		return err
+	}
+	h.count++
+	return htmlTemplate.ExecuteTemplate(h.w, "row", e)
+}
+
+func (h *htmlWriter) Close() error {
+	if err := h.writeHead(); err != nil {
+		return err
+	}
+	return htmlTemplate.ExecuteTemplate(h.w, "foot", h.count)
+}
@@ -0,0 +1,108 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+)
+
+// ReadFile reads back entries exported as CSV, NDJSON or list --json. The
+// format is told from the content: a JSON array, JSON objects one per
+// line, or else CSV with the header written by the csv format.
+func ReadFile(path string) ([]browser.HistoryEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := bytes.TrimLeft(data, " \t\r\n\ufeff")
+	var entries []browser.HistoryEntry
+	switch {
+	case len(trimmed) == 0:
+	case trimmed[0] == '[':
+		err = json.Unmarshal(trimmed, &entries)
+	case trimmed[0] == '{':
+		entries, err = readNDJSON(bytes.NewReader(trimmed))
+	default:
+		entries, err = readCSV(bytes.NewReader(trimmed))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+func readNDJSON(r io.Reader) ([]browser.HistoryEntry, error) {
+	var entries []browser.HistoryEntry
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var e browser.HistoryEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
+
+func readCSV(r io.Reader) ([]browser.HistoryEntry, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	col := make(map[string]int)
+	for i, name := range header {
+		col[name] = i
+	}
+	for _, name := range []string{"url", "visit_time"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("CSV has no %s column", name)
+		}
+	}
+	field := func(rec []string, name string) string {
+		if i, ok := col[name]; ok && i < len(rec) {
+			return rec[i]
+		}
+		return ""
+	}
+
+	var entries []browser.HistoryEntry
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		t, err := time.Parse(time.RFC3339Nano, field(rec, "visit_time"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: visit_time: %w", line, err)
+		}
+		e := browser.HistoryEntry{
+			URL:       field(rec, "url"),
+			Title:     field(rec, "title"),
+			VisitTime: t,
+			Browser:   field(rec, "browser"),
+		}
+		if s := field(rec, "visit_count"); s != "" {
+			if e.VisitCount, err = strconv.Atoi(s); err != nil {
+				return nil, fmt.Errorf("line %d: visit_count: %w", line, err)
+			}
+		}
+		entries = append(entries, e)
+	}
+}
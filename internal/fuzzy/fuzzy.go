@@ -0,0 +1,94 @@
+// Package fuzzy scores subsequence matches of a typed pattern in a string,
+// as in editors' "go to file": every pattern rune must appear in order,
+// case-insensitively, and compact matches at word starts rank higher.
+package fuzzy
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	scoreMatch       = 16
+	bonusBoundary    = 8 // match at the start of text or after a separator
+	bonusConsecutive = 8 // match right after the previous match
+	penaltyGap       = 1 // per unmatched rune inside the match span
+)
+
+// Match is a successful match of a pattern in a text.
+type Match struct {
+	Score     int
+	Positions []int // byte offsets in text of the matched runes, ascending
+}
+
+// Find matches pattern against text. An empty pattern matches with score 0.
+func Find(pattern, text string) (Match, bool) {
+	pat := []rune(pattern)
+	for i, r := range pat {
+		pat[i] = unicode.ToLower(r)
+	}
+	if len(pat) == 0 {
+		return Match{}, true
+	}
+
+	var runes []rune
+	var offsets []int
+	for i, r := range text {
+		runes = append(runes, unicode.ToLower(r))
+		offsets = append(offsets, i)
+	}
+
+	// Find where the first complete match ends, then walk back from there
+	// to the latest possible start, so the span is as short as a greedy
+	// scan allows.
+	end, p := -1, 0
+	for i, r := range runes {
+		if r == pat[p] {
+			p++
+			if p == len(pat) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return Match{}, false
+	}
+	start, p := end, len(pat)-1
+	for ; start >= 0; start-- {
+		if runes[start] == pat[p] {
+			if p == 0 {
+				break
+			}
+			p--
+		}
+	}
+
+	m := Match{Positions: make([]int, 0, len(pat))}
+	prev, p := -1, 0
+	for i := start; i <= end && p < len(pat); i++ {
+		if runes[i] != pat[p] {
+			continue
+		}
+		m.Score += scoreMatch
+		if i == 0 || isSeparator(text, offsets[i-1]) {
+			m.Score += bonusBoundary
+		}
+		if prev >= 0 {
+			if i == prev+1 {
+				m.Score += bonusConsecutive
+			} else {
+				m.Score -= penaltyGap * (i - prev - 1)
+			}
+		}
+		m.Positions = append(m.Positions, offsets[i])
+		prev = i
+		p++
+	}
+	return m, true
+}
+
+func isSeparator(text string, offset int) bool {
+	r, _ := utf8.DecodeRuneInString(text[offset:])
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,47 @@
+package fuzzy
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		ok            bool
+		positions     []int
+	}{
+		{"", "anything", true, nil},
+		{"ghub", "https://GitHub.com", true, []int{8, 11, 12, 13}},
+		{"hst", "histctl", true, []int{0, 2, 3}},
+		{"ctl", "histctl", true, []int{4, 5, 6}},
+		{"xyz", "histctl", false, nil},
+		{"lh", "hl", false, nil},
+		{"bü", "Bücher", true, []int{0, 1}},
+	}
+	for _, tt := range tests {
+		m, ok := Find(tt.pattern, tt.text)
+		if ok != tt.ok {
+			t.Errorf("Find(%q, %q) ok = %v, want %v", tt.pattern, tt.text, ok, tt.ok)
+			continue
+		}
+		if ok && !slices.Equal(m.Positions, tt.positions) {
+			t.Errorf("Find(%q, %q) positions = %v, want %v", tt.pattern, tt.text, m.Positions, tt.positions)
+		}
+	}
+}
+
+func TestFindRanking(t *testing.T) {
+	better := []struct{ pattern, hi, lo string }{
+		{"doc", "go.dev/doc", "go.dev/d-o-c"},           // consecutive
+		{"gd", "go.dev", "agxd"},                        // word starts
+		{"news", "news.ycombinator.com", "nxexwxs.com"}, // compact span
+	}
+	for _, tt := range better {
+		hi, _ := Find(tt.pattern, tt.hi)
+		lo, _ := Find(tt.pattern, tt.lo)
+		if hi.Score <= lo.Score {
+			t.Errorf("Find(%q): %q scored %d, want more than %q at %d", tt.pattern, tt.hi, hi.Score, tt.lo, lo.Score)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Package group aggregates history visits by URL or host, so a page visited
+// hundreds of times shows as one row with its visit count.
+package group
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+)
+
+// Key is what visits are grouped by.
+type Key string
+
+const (
+	ByURL  Key = "url"
+	ByHost Key = "host"
+)
+
+// ParseKey parses a --group value.
+func ParseKey(s string) (Key, error) {
+	switch k := Key(strings.ToLower(s)); k {
+	case ByURL, ByHost:
+		return k, nil
+	}
+	return "", fmt.Errorf("invalid group %q: want url or host", s)
+}
+
+// Of returns the group key of e: its URL, or its lowercased host. Entries
+// without a host are grouped by URL.
+func (k Key) Of(e browser.HistoryEntry) string {
+	if k == ByHost {
+		if host := strings.ToLower(browser.FieldHost.Value(e)); host != "" {
+			return host
+		}
+	}
+	return e.URL
+}
+
+// Order is how groups are sorted.
+type Order string
+
+const (
+	MostVisited Order = "visits" // most visits first, then most recent
+	MostRecent  Order = "recent" // most recent last visit first
+)
+
+// Group is the visits sharing one URL or host.
+type Group struct {
+	Key      string    `json:"key"`             // the URL or host
+	Title    string    `json:"title,omitempty"` // of the latest visit; URL groups only
+	Visits   int       `json:"visits"`
+	First    time.Time `json:"first_visit"`
+	Last     time.Time `json:"last_visit"`
+	Browsers []string  `json:"browsers"`
+	Entries  []int     `json:"-"` // indices of the visits in the grouped slice
+}
+
+// By groups entries by key, most visited first and then most recent.
+func By(entries []browser.HistoryEntry, key Key) []Group {
+	var groups []Group
+	index := make(map[string]int)
+	for i, e := range entries {
+		k := key.Of(e)
+		gi, ok := index[k]
+		if !ok {
+			gi = len(groups)
+			index[k] = gi
+			groups = append(groups, Group{Key: k, First: e.VisitTime, Last: e.VisitTime})
+		}
+		g := &groups[gi]
+		g.Visits++
+		g.Entries = append(g.Entries, i)
+		if e.VisitTime.Before(g.First) {
+			g.First = e.VisitTime
+		}
+		if !e.VisitTime.Before(g.Last) {
+			g.Last = e.VisitTime
+			if key == ByURL {
+				g.Title = e.Title
+			}
+		}
+		if !slices.Contains(g.Browsers, e.Browser) {
+			g.Browsers = append(g.Browsers, e.Browser)
+		}
+	}
+	for i := range groups {
+		slices.Sort(groups[i].Browsers)
+	}
+	Sort(groups, MostVisited)
+	return groups
+}
+
+// Sort orders groups in place.
+func Sort(groups []Group, o Order) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		if o == MostVisited && groups[i].Visits != groups[j].Visits {
+			return groups[i].Visits > groups[j].Visits
+		}
+		return groups[i].Last.After(groups[j].Last)
+	})
+}
@@ -0,0 +1,75 @@
+package group
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+)
+
+func TestBy(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	entries := []browser.HistoryEntry{
+		{URL: "https://github.com/a", Title: "A new", VisitTime: t0.Add(3 * time.Hour), Browser: "firefox"},
+		{URL: "https://go.dev/doc", Title: "Go", VisitTime: t0.Add(2 * time.Hour), Browser: "chrome"},
+		{URL: "https://github.com/a", Title: "A old", VisitTime: t0, Browser: "chrome"},
+		{URL: "https://GitHub.com/b", Title: "B", VisitTime: t0.Add(time.Hour), Browser: "chrome"},
+		{URL: "file:///tmp/x.html", Title: "X", VisitTime: t0, Browser: "chrome"},
+	}
+
+	groups := By(entries, ByURL)
+	if len(groups) != 4 {
+		t.Fatalf("By(url) = %d groups, want 4", len(groups))
+	}
+	g := groups[0]
+	if g.Key != "https://github.com/a" || g.Visits != 2 || g.Title != "A new" {
+		t.Errorf("By(url)[0] = %+v, want github.com/a with 2 visits and the latest title", g)
+	}
+	if !g.First.Equal(t0) || !g.Last.Equal(t0.Add(3*time.Hour)) {
+		t.Errorf("By(url)[0] first/last = %v/%v", g.First, g.Last)
+	}
+	if !slices.Equal(g.Browsers, []string{"chrome", "firefox"}) || !slices.Equal(g.Entries, []int{0, 2}) {
+		t.Errorf("By(url)[0] browsers %v entries %v", g.Browsers, g.Entries)
+	}
+	if groups[1].Key != "https://go.dev/doc" {
+		t.Errorf("By(url)[1] = %q, want the most recent single visit", groups[1].Key)
+	}
+
+	groups = By(entries, ByHost)
+	var keys []string
+	for _, g := range groups {
+		keys = append(keys, g.Key)
+	}
+	if want := []string{"github.com", "go.dev", "file:///tmp/x.html"}; !slices.Equal(keys, want) {
+		t.Errorf("By(host) keys = %v, want %v", keys, want)
+	}
+	if groups[0].Visits != 3 || groups[0].Title != "" {
+		t.Errorf("By(host)[0] = %+v, want 3 visits and no title", groups[0])
+	}
+}
+
+func TestSort(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	groups := []Group{
+		{Key: "busy", Visits: 9, Last: t0},
+		{Key: "recent", Visits: 1, Last: t0.Add(time.Hour)},
+	}
+	Sort(groups, MostRecent)
+	if groups[0].Key != "recent" {
+		t.Errorf("Sort(recent) first = %q", groups[0].Key)
+	}
+	Sort(groups, MostVisited)
+	if groups[0].Key != "busy" {
+		t.Errorf("Sort(visits) first = %q", groups[0].Key)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	if k, err := ParseKey("Host"); err != nil || k != ByHost {
+		t.Errorf("ParseKey(Host) = %q, %v", k, err)
+	}
+	if _, err := ParseKey("day"); err == nil {
+		t.Error("ParseKey(day) should fail")
+	}
+}
@@ -0,0 +1,116 @@
+package pagecache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+// A Chrome simple cache entry file (<hash>_0) holds a header, the key, the
+// body (stream 1) and its EOF record, then the pickled response info
+// (stream 0), an optional SHA-256 of the key and a final EOF record. All
+// integers are little-endian.
+const (
+	simpleInitialMagic = 0xfcfb6d1ba7725c30
+	simpleFinalMagic   = 0xf4fa6f45970d41d8
+	simpleHeaderSize   = 24 // magic, version, key length, key hash, padding
+	simpleEOFSize      = 24 // magic, flags, CRC-32, stream size, padding
+	simpleHasKeySHA256 = 2  // EOF flag: a key SHA-256 precedes the record
+)
+
+func isSimpleEntry(r io.ReaderAt) bool {
+	var magic [8]byte
+	if _, err := r.ReadAt(magic[:], 0); err != nil {
+		return false
+	}
+	return binary.LittleEndian.Uint64(magic[:]) == simpleInitialMagic
+}
+
+// simpleEOF reads the EOF record at off and returns its flags and stream size.
+func simpleEOF(r io.ReaderAt, off int64) (flags uint32, size int64, err error) {
+	var rec [simpleEOFSize]byte
+	if off < 0 {
+		return 0, 0, ErrNotEntry
+	}
+	if _, err := r.ReadAt(rec[:], off); err != nil {
+		return 0, 0, err
+	}
+	if binary.LittleEndian.Uint64(rec[:]) != simpleFinalMagic {
+		return 0, 0, ErrNotEntry
+	}
+	return binary.LittleEndian.Uint32(rec[8:]), int64(binary.LittleEndian.Uint32(rec[16:])), nil
+}
+
+func readSimple(r io.ReaderAt, size int64) (*Entry, error) {
+	var hdr [simpleHeaderSize]byte
+	if _, err := r.ReadAt(hdr[:], 0); err != nil {
+		return nil, ErrNotEntry
+	}
+	keyLen := int64(binary.LittleEndian.Uint32(hdr[12:]))
+	if simpleHeaderSize+keyLen > size {
+		return nil, ErrNotEntry
+	}
+	key := make([]byte, keyLen)
+	if _, err := r.ReadAt(key, simpleHeaderSize); err != nil {
+		return nil, err
+	}
+
+	flags, size0, err := simpleEOF(r, size-simpleEOFSize)
+	if err != nil {
+		return nil, err
+	}
+	end0 := size - simpleEOFSize
+	if flags&simpleHasKeySHA256 != 0 {
+		end0 -= 32
+	}
+	start0 := end0 - size0
+	_, size1, err := simpleEOF(r, start0-simpleEOFSize)
+	if err != nil {
+		return nil, err
+	}
+	start1 := start0 - simpleEOFSize - size1
+	if start1 != simpleHeaderSize+keyLen {
+		return nil, ErrNotEntry
+	}
+
+	info := make([]byte, size0)
+	if _, err := r.ReadAt(info, start0); err != nil {
+		return nil, err
+	}
+	status, header, err := parseHeaders(pickledHeaders(info))
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{
+		URL:        simpleKeyURL(string(key)),
+		Status:     status,
+		Header:     header,
+		bodyOffset: start1,
+		bodySize:   size1,
+	}, nil
+}
+
+// pickledHeaders finds the raw headers in a pickled HttpResponseInfo: the
+// status line and header lines, each ending in a NUL, then another NUL.
+func pickledHeaders(info []byte) []string {
+	i := bytes.Index(info, []byte("HTTP/"))
+	if i < 0 {
+		return nil
+	}
+	raw := info[i:]
+	if end := bytes.Index(raw, []byte("\x00\x00")); end >= 0 {
+		raw = raw[:end]
+	}
+	return strings.Split(string(raw), "\x00")
+}
+
+// simpleKeyURL returns the URL of a cache key. Keys of partitioned caches
+// put the isolation key first, as in
+// "1/0/_dk_https://a.com https://a.com https://a.com/page"; the URL is last.
+func simpleKeyURL(key string) string {
+	if i := strings.LastIndexByte(key, ' '); i >= 0 {
+		return key[i+1:]
+	}
+	return key
+}
@@ -0,0 +1,86 @@
+package pagecache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+// A Firefox cache2 entry file holds the body, then the metadata: a hash of
+// it, a hash per 256 KiB chunk of the body, a header, the key and
+// NUL-separated name and value pairs such as "response-head". The file
+// ends in the offset of the metadata. All integers are big-endian.
+const (
+	cache2ChunkSize = 256 * 1024
+	cache2MaxMeta   = 1 << 20 // larger metadata means this is not an entry
+)
+
+func readCache2(r io.ReaderAt, size int64) (*Entry, error) {
+	var tail [4]byte
+	if size < 4 {
+		return nil, ErrNotEntry
+	}
+	if _, err := r.ReadAt(tail[:], size-4); err != nil {
+		return nil, err
+	}
+	bodySize := int64(binary.BigEndian.Uint32(tail[:]))
+	chunks := (bodySize + cache2ChunkSize - 1) / cache2ChunkSize
+	metaStart := bodySize + 4 + 2*chunks
+	if metaStart > size-4 || size-4-metaStart > cache2MaxMeta {
+		return nil, ErrNotEntry
+	}
+	meta := make([]byte, size-4-metaStart)
+	if _, err := r.ReadAt(meta, metaStart); err != nil {
+		return nil, err
+	}
+
+	// version, fetch count, last fetched, last modified, frecency,
+	// expiration time, key size and, from version 2, flags
+	if len(meta) < 28 {
+		return nil, ErrNotEntry
+	}
+	version := binary.BigEndian.Uint32(meta)
+	hdrSize := 28
+	switch {
+	case version == 1:
+	case version == 2 || version == 3:
+		hdrSize = 32
+	default:
+		return nil, ErrNotEntry
+	}
+	keySize := int(binary.BigEndian.Uint32(meta[24:]))
+	if hdrSize+keySize+1 > len(meta) {
+		return nil, ErrNotEntry
+	}
+	key := string(meta[hdrSize : hdrSize+keySize])
+	elements := bytes.Split(meta[hdrSize+keySize+1:], []byte{0})
+
+	var head string
+	for i := 0; i+1 < len(elements); i += 2 {
+		if string(elements[i]) == "response-head" {
+			head = string(elements[i+1])
+			break
+		}
+	}
+	status, header, err := parseHeaders(strings.Split(strings.TrimRight(head, "\r\n"), "\r\n"))
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{
+		URL:      cache2KeyURL(key),
+		Status:   status,
+		Header:   header,
+		bodySize: bodySize,
+	}, nil
+}
+
+// cache2KeyURL returns the URL of a cache key. Keys start with tags such
+// as "a," or "O^partitionKey=%28https%2Cexample.com%29," and a colon.
+func cache2KeyURL(key string) string {
+	if i := strings.Index(key, ":http"); i >= 0 {
+		return key[i+1:]
+	}
+	_, url, _ := strings.Cut(key, ":")
+	return url
+}
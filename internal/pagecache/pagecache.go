@@ -0,0 +1,268 @@
+// Package pagecache reads pages from browser disk caches: the entries of
+// Chrome's simple cache (Cache_Data) and of Firefox's cache2. Only the
+// files on disk are read; nothing is fetched.
+package pagecache
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+var (
+	// ErrNotEntry is returned by Open for files that are not cache entries.
+	ErrNotEntry = errors.New("not a cache entry")
+	// ErrNotText is returned by Text for responses that are not a page.
+	ErrNotText = errors.New("not a cached page")
+)
+
+// maxText caps the text kept of one page, and maxBody the decoded body
+// read for it.
+const (
+	maxText = 1 << 20
+	maxBody = 16 << 20
+)
+
+// Entry is a cached HTTP response. The body is read on demand.
+type Entry struct {
+	URL    string
+	Status int
+	Header http.Header
+
+	path       string
+	bodyOffset int64
+	bodySize   int64
+}
+
+// Open reads the URL and response headers of the cache entry at path,
+// which may be from either browser.
+func Open(path string) (*Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var e *Entry
+	if isSimpleEntry(f) {
+		e, err = readSimple(f, info.Size())
+	} else {
+		e, err = readCache2(f, info.Size())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	e.path = path
+	return e, nil
+}
+
+// Body returns the response body as stored, still content-encoded.
+func (e *Entry) Body() ([]byte, error) {
+	f, err := os.Open(e.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	body := make([]byte, e.bodySize)
+	if _, err := f.ReadAt(body, e.bodyOffset); err != nil {
+		return nil, fmt.Errorf("read %s: %w", e.path, err)
+	}
+	return body, nil
+}
+
+// Text returns the title and readable text of a successful HTML or plain
+// text response. Scripts, styles and markup are left out and whitespace is
+// collapsed. Other responses return ErrNotText.
+func (e *Entry) Text() (title, text string, err error) {
+	contentType := e.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if e.Status != http.StatusOK || (mediaType != "text/html" && mediaType != "application/xhtml+xml" && mediaType != "text/plain") {
+		return "", "", ErrNotText
+	}
+	body, err := e.Body()
+	if err != nil {
+		return "", "", err
+	}
+	decoded, err := decode(bytes.NewReader(body), e.Header.Get("Content-Encoding"))
+	if err != nil {
+		return "", "", err
+	}
+	if c, ok := decoded.(io.Closer); ok {
+		defer c.Close()
+	}
+	// Bound what a compression bomb can expand to.
+	r := io.LimitReader(decoded, maxBody)
+	if r, err = charset.NewReader(r, contentType); err != nil {
+		return "", "", err
+	}
+	if mediaType == "text/plain" {
+		data, err := io.ReadAll(io.LimitReader(r, maxText))
+		if err != nil {
+			return "", "", err
+		}
+		return "", strings.Join(strings.Fields(string(data)), " "), nil
+	}
+	return htmlText(r)
+}
+
+// decode undoes a Content-Encoding, applied in the order listed.
+func decode(r io.Reader, encoding string) (io.Reader, error) {
+	codings := strings.Split(encoding, ",")
+	for i := len(codings) - 1; i >= 0; i-- {
+		var err error
+		switch c := strings.ToLower(strings.TrimSpace(codings[i])); c {
+		case "", "identity":
+		case "gzip", "x-gzip":
+			r, err = gzip.NewReader(r)
+		case "deflate":
+			// Servers send zlib streams and sometimes raw deflate.
+			data, rerr := io.ReadAll(r)
+			if rerr != nil {
+				return nil, rerr
+			}
+			if r, err = zlib.NewReader(bytes.NewReader(data)); err != nil {
+				r, err = flate.NewReader(bytes.NewReader(data)), nil
+			}
+		case "br":
+			r = brotli.NewReader(r)
+		case "zstd":
+			r, err = decodeZstd(r)
+		default:
+			return nil, fmt.Errorf("unsupported content encoding %q", c)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", codings[i], err)
+		}
+	}
+	return r, nil
+}
+
+// decodeZstd decodes a zstd stream with bounded memory: the body is read
+// on demand, like the other encodings, and a frame may not ask for a
+// window larger than maxZstdWindow.
+func decodeZstd(r io.Reader) (io.Reader, error) {
+	d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(maxZstdWindow))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdReader{d: d}, nil
+}
+
+// maxZstdWindow caps the zstd window, the memory a stream may ask for.
+const maxZstdWindow = 8 << 20
+
+// zstdReader closes its decoder at the end of the stream, on an error or
+// when closed.
+type zstdReader struct {
+	d *zstd.Decoder
+}
+
+func (z *zstdReader) Close() error {
+	if z.d != nil {
+		z.d.Close()
+		z.d = nil
+	}
+	return nil
+}
+
+func (z *zstdReader) Read(p []byte) (int, error) {
+	if z.d == nil {
+		return 0, io.EOF
+	}
+	n, err := z.d.Read(p)
+	if err != nil {
+		z.d.Close()
+		z.d = nil
+	}
+	return n, err
+}
+
+// skipped are the elements whose content is not page text.
+var skipped = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+	"svg": true, "iframe": true, "object": true,
+}
+
+// htmlText returns the title and text of an HTML document.
+func htmlText(r io.Reader) (title, text string, err error) {
+	z := html.NewTokenizer(r)
+	var b strings.Builder
+	depth := 0 // nesting of skipped elements
+	inTitle := false
+	for b.Len() < maxText {
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return "", "", err
+			}
+			return strings.Join(strings.Fields(title), " "), strings.Join(strings.Fields(b.String()), " "), nil
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			switch {
+			case string(name) == "title":
+				inTitle = true
+			case skipped[string(name)]:
+				depth++
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch {
+			case string(name) == "title":
+				inTitle = false
+			case skipped[string(name)] && depth > 0:
+				depth--
+			}
+		case html.TextToken:
+			switch {
+			case inTitle:
+				title += string(z.Text())
+			case depth == 0:
+				b.Write(z.Text())
+				b.WriteByte(' ')
+			}
+		}
+	}
+	return strings.Join(strings.Fields(title), " "), strings.Join(strings.Fields(b.String()), " "), nil
+}
+
+// parseHeaders parses a status line and header lines.
+func parseHeaders(lines []string) (int, http.Header, error) {
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "HTTP/") {
+		return 0, nil, fmt.Errorf("no response headers")
+	}
+	fields := strings.Fields(lines[0])
+	if len(fields) < 2 {
+		return 0, nil, fmt.Errorf("bad status line %q", lines[0])
+	}
+	status, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, nil, fmt.Errorf("bad status line %q", lines[0])
+	}
+	header := make(http.Header)
+	for _, line := range lines[1:] {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		header.Add(textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name)), strings.TrimSpace(value))
+	}
+	return status, header, nil
+}
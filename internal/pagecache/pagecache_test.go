@@ -0,0 +1,179 @@
+package pagecache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// simpleEntry builds a Chrome simple cache entry file.
+func simpleEntry(key string, headers []string, body []byte) []byte {
+	var b bytes.Buffer
+	hdr := make([]byte, simpleHeaderSize)
+	binary.LittleEndian.PutUint64(hdr, simpleInitialMagic)
+	binary.LittleEndian.PutUint32(hdr[8:], 5)
+	binary.LittleEndian.PutUint32(hdr[12:], uint32(len(key)))
+	b.Write(hdr)
+	b.WriteString(key)
+	b.Write(body)
+	b.Write(simpleEOFRecord(0, len(body)))
+
+	// A pickle of the response info: payload size, flags, request and
+	// response times, then the raw headers.
+	info := make([]byte, 24)
+	info = append(info, strings.Join(headers, "\x00")+"\x00\x00"...)
+	b.Write(info)
+	b.Write(make([]byte, 32)) // key SHA-256
+	b.Write(simpleEOFRecord(simpleHasKeySHA256, len(info)))
+	return b.Bytes()
+}
+
+func simpleEOFRecord(flags uint32, size int) []byte {
+	rec := make([]byte, simpleEOFSize)
+	binary.LittleEndian.PutUint64(rec, simpleFinalMagic)
+	binary.LittleEndian.PutUint32(rec[8:], flags)
+	binary.LittleEndian.PutUint32(rec[16:], uint32(size))
+	return rec
+}
+
+// cache2Entry builds a Firefox cache2 entry file.
+func cache2Entry(key, head string, body []byte) []byte {
+	var b bytes.Buffer
+	b.Write(body)
+	chunks := (len(body) + cache2ChunkSize - 1) / cache2ChunkSize
+	b.Write(make([]byte, 4+2*chunks)) // metadata and chunk hashes
+	hdr := make([]byte, 32)
+	binary.BigEndian.PutUint32(hdr, 3)
+	binary.BigEndian.PutUint32(hdr[24:], uint32(len(key)))
+	b.Write(hdr)
+	b.WriteString(key + "\x00")
+	b.WriteString("request-method\x00GET\x00response-head\x00" + head + "\x00net-response-time-onstart\x0012\x00")
+	binary.Write(&b, binary.BigEndian, uint32(len(body)))
+	return b.Bytes()
+}
+
+func writeEntry(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "entry")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const page = `<!DOCTYPE html><html><head><title>Go  scheduler</title>
+<style>body { color: red }</style><script>var hidden = "secret";</script></head>
+<body><h1>The Go scheduler</h1><p>Goroutines are
+multiplexed onto threads.</p><noscript>Enable JS</noscript></body></html>`
+
+func TestChromeEntry(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(page))
+	w.Close()
+	data := simpleEntry("1/0/_dk_https://go.dev https://go.dev https://go.dev/blog/sched",
+		[]string{"HTTP/1.1 200", "content-type: text/html; charset=utf-8", "content-encoding: gzip"}, gz.Bytes())
+
+	e, err := Open(writeEntry(t, data))
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	if e.URL != "https://go.dev/blog/sched" || e.Status != 200 || e.Header.Get("Content-Encoding") != "gzip" {
+		t.Errorf("entry = %+v", e)
+	}
+	title, text, err := e.Text()
+	if err != nil {
+		t.Fatalf("Text() error: %v", err)
+	}
+	if title != "Go scheduler" {
+		t.Errorf("title = %q", title)
+	}
+	if want := "The Go scheduler Goroutines are multiplexed onto threads."; text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+}
+
+func TestFirefoxEntry(t *testing.T) {
+	var br bytes.Buffer
+	w := brotli.NewWriter(&br)
+	w.Write([]byte(page))
+	w.Close()
+	head := "HTTP/2 200 \r\ncontent-type: text/html\r\ncontent-encoding: br\r\n"
+	data := cache2Entry("O^partitionKey=%28https%2Cgo.dev%29,a,:https://go.dev/blog/sched", head, br.Bytes())
+
+	e, err := Open(writeEntry(t, data))
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	if e.URL != "https://go.dev/blog/sched" || e.Status != 200 {
+		t.Errorf("entry = %+v", e)
+	}
+	_, text, err := e.Text()
+	if err != nil || !strings.Contains(text, "multiplexed onto threads") || strings.Contains(text, "secret") {
+		t.Errorf("Text() = %q, %v", text, err)
+	}
+}
+
+func TestText(t *testing.T) {
+	latin1 := []byte("<p>caf\xe9</p>")
+	tests := []struct {
+		name    string
+		headers []string
+		body    []byte
+		want    string
+		err     error
+	}{
+		{"charset", []string{"HTTP/1.1 200 OK", "Content-Type: text/html; charset=iso-8859-1"}, latin1, "café", nil},
+		{"plain text", []string{"HTTP/1.1 200 OK", "Content-Type: text/plain"}, []byte("  a\n b "), "a b", nil},
+		{"image", []string{"HTTP/1.1 200 OK", "Content-Type: image/png"}, []byte("\x89PNG"), "", ErrNotText},
+		{"redirect", []string{"HTTP/1.1 301 Moved", "Content-Type: text/html", "Location: /"}, nil, "", ErrNotText},
+	}
+	for _, tt := range tests {
+		e, err := Open(writeEntry(t, simpleEntry("https://example.com/", tt.headers, tt.body)))
+		if err != nil {
+			t.Fatalf("%s: Open() error: %v", tt.name, err)
+		}
+		_, text, err := e.Text()
+		if !errors.Is(err, tt.err) || text != tt.want {
+			t.Errorf("%s: Text() = %q, %v; want %q, %v", tt.name, text, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestTextZstdBomb(t *testing.T) {
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := enc.EncodeAll(bytes.Repeat([]byte("a "), 64<<20), nil) // 128 MiB of text
+	enc.Close()
+	e, err := Open(writeEntry(t, simpleEntry("https://example.com/",
+		[]string{"HTTP/1.1 200 OK", "Content-Type: text/plain", "Content-Encoding: zstd"}, body)))
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	_, text, err := e.Text()
+	if err != nil || text == "" || len(text) > maxText {
+		t.Errorf("Text() = %d bytes, %v; want at most %d", len(text), err, maxText)
+	}
+}
+
+func TestOpenNotEntry(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"index":     []byte("\x00\x00\x00\x00some index data\x00\x00\x00\x08"),
+		"truncated": simpleEntry("https://example.com/", []string{"HTTP/1.1 200"}, []byte("body"))[:40],
+	} {
+		if _, err := Open(writeEntry(t, data)); err == nil {
+			t.Errorf("Open(%s) should fail", name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package plan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+)
+
+// Version is the plan file format version.
+const Version = 1
+
+// File is a reviewed delete plan written by `delete --plan` and executed by
+// `apply`.
+type File struct {
+	Version  int               `json:"version"`
+	Created  time.Time         `json:"created"`
+	Pattern  string            `json:"pattern"`
+	Domain   string            `json:"domain,omitempty"`
+	Fields   map[string]string `json:"fields,omitempty"` // field regexes such as --title
+	Filter   string            `json:"filter,omitempty"` // --filter expression
+	Browsers []Browser         `json:"browsers"`
+}
+
+// Browser holds the entries planned for deletion from one history database.
+type Browser struct {
+	Name              string      `json:"name"`
+	DBPath            string      `json:"db_path"`
+	Fingerprint       Fingerprint `json:"fingerprint"`
+	IncludeBookmarked bool        `json:"include_bookmarked,omitempty"`
+	Entries           []Entry     `json:"entries"`
+}
+
+// Entry identifies a single visit.
+type Entry struct {
+	ItemID    int64     `json:"item_id"`
+	VisitID   int64     `json:"visit_id"`
+	URL       string    `json:"url"`
+	VisitTime time.Time `json:"visit_time"`
+}
+
+// Fingerprint identifies the contents of a database, including its WAL.
+type Fingerprint struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	SHA256  string    `json:"sha256"`
+}
+
+// NewBrowser plans the deletion of entries from b's database.
+func NewBrowser(b browser.Browser, entries []browser.HistoryEntry, includeBookmarked bool) (Browser, error) {
+	dbPath, err := b.DBPath()
+	if err != nil {
+		return Browser{}, err
+	}
+	fp, err := FingerprintDB(dbPath)
+	if err != nil {
+		return Browser{}, err
+	}
+	pb := Browser{
+		Name:              b.Name(),
+		DBPath:            dbPath,
+		Fingerprint:       fp,
+		IncludeBookmarked: includeBookmarked,
+		Entries:           make([]Entry, len(entries)),
+	}
+	for i, e := range entries {
+		pb.Entries[i] = Entry{ItemID: e.ItemID, VisitID: e.VisitID, URL: e.URL, VisitTime: e.VisitTime}
+	}
+	return pb, nil
+}
+
+// FingerprintDB hashes the database at path together with its -wal file,
+// if present.
+func FingerprintDB(path string) (Fingerprint, error) {
+	var fp Fingerprint
+	h := sha256.New()
+	for i, p := range []string{path, path + "-wal"} {
+		f, err := os.Open(p)
+		if err != nil {
+			if i > 0 && os.IsNotExist(err) {
+				continue
+			}
+			return fp, fmt.Errorf("fingerprint %s: %w", p, err)
+		}
+		info, err := f.Stat()
+		if err == nil {
+			fp.Size += info.Size()
+			if info.ModTime().After(fp.ModTime) {
+				fp.ModTime = info.ModTime().UTC()
+			}
+			_, err = io.Copy(h, f)
+		}
+		f.Close()
+		if err != nil {
+			return fp, fmt.Errorf("fingerprint %s: %w", p, err)
+		}
+	}
+	fp.SHA256 = hex.EncodeToString(h.Sum(nil))
+	return fp, nil
+}
+
+// HistoryEntries converts the planned entries back into entries that can be
+// passed to Browser.Delete.
+func (pb Browser) HistoryEntries() []browser.HistoryEntry {
+	out := make([]browser.HistoryEntry, len(pb.Entries))
+	for i, e := range pb.Entries {
+		out[i] = browser.HistoryEntry{
+			URL:       e.URL,
+			VisitTime: e.VisitTime,
+			Browser:   pb.Name,
+			ItemID:    e.ItemID,
+			VisitID:   e.VisitID,
+		}
+	}
+	return out
+}
+
+// Verify checks that every planned visit still exists unchanged among
+// current, the browser's full history. It is needed only when the database
+// fingerprint no longer matches.
+func (pb Browser) Verify(current []browser.HistoryEntry) error {
+	byVisit := make(map[int64]browser.HistoryEntry, len(current))
+	for _, e := range current {
+		byVisit[e.VisitID] = e
+	}
+	for _, want := range pb.Entries {
+		got, ok := byVisit[want.VisitID]
+		if !ok {
+			return fmt.Errorf("visit %d (%s) no longer exists", want.VisitID, want.URL)
+		}
+		if got.ItemID != want.ItemID || got.URL != want.URL || !got.VisitTime.Equal(want.VisitTime) {
+			return fmt.Errorf("visit %d changed: planned %s at %s, now %s at %s",
+				want.VisitID, want.URL, want.VisitTime.Format(time.RFC3339), got.URL, got.VisitTime.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// Write saves the plan as indented JSON.
+func Write(path string, f File) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("write plan: %w", err)
+	}
+	return nil
+}
+
+// Read loads a plan written by Write.
+func Read(path string) (File, error) {
+	var f File
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return f, fmt.Errorf("read plan: %w", err)
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, fmt.Errorf("parse plan %s: %w", path, err)
+	}
+	if f.Version != Version {
+		return f, fmt.Errorf("plan %s has version %d, want %d", path, f.Version, Version)
+	}
+	return f, nil
+}
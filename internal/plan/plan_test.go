@@ -0,0 +1,93 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+)
+
+var planned = []browser.HistoryEntry{
+	{URL: "https://example.com", VisitTime: time.Unix(1717200000, 0).UTC(), Browser: "chrome", ItemID: 1, VisitID: 10},
+	{URL: "https://golang.org", VisitTime: time.Unix(1717286400, 0).UTC(), Browser: "chrome", ItemID: 2, VisitID: 11},
+}
+
+func newPlanBrowser() Browser {
+	pb := Browser{Name: "chrome", Entries: make([]Entry, len(planned))}
+	for i, e := range planned {
+		pb.Entries[i] = Entry{ItemID: e.ItemID, VisitID: e.VisitID, URL: e.URL, VisitTime: e.VisitTime}
+	}
+	return pb
+}
+
+func TestWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	f := File{Version: Version, Created: time.Now().UTC(), Pattern: "example", Browsers: []Browser{newPlanBrowser()}}
+	if err := Write(path, f); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	got, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if len(got.Browsers) != 1 || len(got.Browsers[0].Entries) != 2 {
+		t.Fatalf("Read() = %+v, want 1 browser with 2 entries", got)
+	}
+	entries := got.Browsers[0].HistoryEntries()
+	if entries[1].VisitID != 11 || entries[1].Browser != "chrome" || !entries[1].VisitTime.Equal(planned[1].VisitTime) {
+		t.Errorf("HistoryEntries()[1] = %+v, want %+v", entries[1], planned[1])
+	}
+}
+
+func TestReadWrongVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99}`), 0600); err != nil {
+		t.Fatalf("write plan: %v", err)
+	}
+	if _, err := Read(path); err == nil {
+		t.Fatal("Read() should reject an unknown version")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	pb := newPlanBrowser()
+	current := append([]browser.HistoryEntry{
+		{URL: "https://new.example", VisitTime: time.Now(), ItemID: 3, VisitID: 12},
+	}, planned...)
+	if err := pb.Verify(current); err != nil {
+		t.Errorf("Verify() with extra entries error: %v", err)
+	}
+
+	if err := pb.Verify(planned[:1]); err == nil {
+		t.Error("Verify() should fail when a planned visit is gone")
+	}
+
+	changed := append([]browser.HistoryEntry{}, planned...)
+	changed[0].URL = "https://other.example"
+	if err := pb.Verify(changed); err == nil {
+		t.Error("Verify() should fail when a planned visit changed")
+	}
+}
+
+func TestFingerprintDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "History")
+	if err := os.WriteFile(path, []byte("one"), 0644); err != nil {
+		t.Fatalf("write db: %v", err)
+	}
+	a, err := FingerprintDB(path)
+	if err != nil {
+		t.Fatalf("FingerprintDB() error: %v", err)
+	}
+	if err := os.WriteFile(path+"-wal", []byte("frames"), 0644); err != nil {
+		t.Fatalf("write wal: %v", err)
+	}
+	b, err := FingerprintDB(path)
+	if err != nil {
+		t.Fatalf("FingerprintDB() error: %v", err)
+	}
+	if a.SHA256 == b.SHA256 || b.Size != 9 {
+		t.Errorf("FingerprintDB() = %+v after WAL write, want a new hash and size 9", b)
+	}
+}
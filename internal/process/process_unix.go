@@ -8,8 +8,12 @@ import (
 	"strings"
 )
 
-// IsRunning checks whether a process with the given name is running.
+// IsRunning checks whether a process with the given name is running. An
+// empty name, as of a browser export file, is never running.
 func IsRunning(processName string) (bool, error) {
+	if processName == "" {
+		return false, nil
+	}
 	cmd := exec.Command("pgrep", "-x", processName)
 	output, err := cmd.Output()
 	if err != nil {
@@ -0,0 +1,135 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error is a syntax or value error in a query, located at a token.
+type Error struct {
+	Input string
+	Pos   int // byte offset of the bad token in Input
+	Len   int // byte length of the bad token; 0 at the end of input
+	Msg   string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at column %d", e.Msg, utf8.RuneCountInString(e.Input[:e.Pos])+1)
+}
+
+// Context renders the query with a caret line under the bad token.
+func (e *Error) Context() string {
+	pad := strings.Repeat(" ", utf8.RuneCountInString(e.Input[:e.Pos]))
+	marks := strings.Repeat("^", max(1, utf8.RuneCountInString(e.Input[e.Pos:e.Pos+e.Len])))
+	return e.Input + "\n" + pad + marks
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits a query into tokens. Words run until whitespace or an
+// unbalanced closing parenthesis, so regexes such as title:(a|b) stay whole;
+// double quotes protect spaces inside a word.
+func lex(input string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+			continue
+		case c == '(':
+			toks = append(toks, token{kind: tokLParen, text: "(", pos: i})
+			i++
+			continue
+		case c == ')':
+			toks = append(toks, token{kind: tokRParen, text: ")", pos: i})
+			i++
+			continue
+		}
+
+		start, depth, quote := i, 0, -1
+	word:
+		for i < len(input) {
+			c := input[i]
+			if quote >= 0 {
+				if c == '\\' && i+1 < len(input) {
+					i += 2
+					continue
+				}
+				if c == '"' {
+					quote = -1
+				}
+				i++
+				continue
+			}
+			switch c {
+			case '"':
+				quote = i
+			case ' ', '\t', '\n':
+				break word
+			case '(':
+				depth++
+			case ')':
+				if depth == 0 {
+					break word
+				}
+				depth--
+			}
+			i++
+		}
+		if quote >= 0 {
+			return nil, &Error{Input: input, Pos: quote, Len: i - quote, Msg: "unterminated quote"}
+		}
+
+		text := input[start:i]
+		kind := tokWord
+		switch text {
+		case "AND":
+			kind = tokAnd
+		case "OR":
+			kind = tokOr
+		case "NOT":
+			kind = tokNot
+		}
+		toks = append(toks, token{kind: kind, text: text, pos: start})
+	}
+	return append(toks, token{kind: tokEOF, pos: len(input)}), nil
+}
+
+// unquote removes double quotes from a value, keeping escaped quotes.
+// Other backslashes are left for the regex.
+func unquote(s string) string {
+	if !strings.Contains(s, `"`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == '"':
+			b.WriteByte('"')
+			i++
+		case s[i] == '"':
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,466 @@
+// Package query parses filter expressions such as
+//
+//	host:github.com AND NOT path:/settings AND after:2026-01-01 AND visits>3
+//
+// and compiles them to browser.ListOptions. Terms are field:regex for the
+// fields in browser.Fields, domain:example.com, after: and before: with a
+// date or an age such as 30d, and visits with >, >=, <, <= or =. A bare word,
+// including one such as localhost:8080 whose prefix is not a field, is a
+// regex on the URL. Terms combine with AND (or just a space), OR, NOT
+// and parentheses; AND binds tighter than OR.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/domain"
+)
+
+// Expr is a parsed query.
+type Expr interface {
+	Match(e browser.HistoryEntry) bool
+}
+
+type andExpr []Expr
+
+func (a andExpr) Match(e browser.HistoryEntry) bool {
+	for _, x := range a {
+		if !x.Match(e) {
+			return false
+		}
+	}
+	return true
+}
+
+type orExpr []Expr
+
+func (o orExpr) Match(e browser.HistoryEntry) bool {
+	for _, x := range o {
+		if x.Match(e) {
+			return true
+		}
+	}
+	return false
+}
+
+type notExpr struct{ x Expr }
+
+func (n notExpr) Match(e browser.HistoryEntry) bool { return !n.x.Match(e) }
+
+type fieldTerm struct{ m browser.FieldMatch }
+
+func (t fieldTerm) Match(e browser.HistoryEntry) bool {
+	return t.m.Pattern.MatchString(t.m.Field.Value(e))
+}
+
+type domainTerm struct{ d string }
+
+func (t domainTerm) Match(e browser.HistoryEntry) bool { return domain.MatchURL(e.URL, t.d) }
+
+// timeTerm is after: (visits at or after t) or before: (visits before t).
+type timeTerm struct {
+	after bool
+	t     time.Time
+}
+
+func (t timeTerm) Match(e browser.HistoryEntry) bool {
+	if t.after {
+		return !e.VisitTime.Before(t.t)
+	}
+	return e.VisitTime.Before(t.t)
+}
+
+type visitsTerm struct {
+	op string
+	n  int
+}
+
+func (t visitsTerm) Match(e browser.HistoryEntry) bool {
+	switch t.op {
+	case ">":
+		return e.VisitCount > t.n
+	case ">=":
+		return e.VisitCount >= t.n
+	case "<":
+		return e.VisitCount < t.n
+	case "<=":
+		return e.VisitCount <= t.n
+	}
+	return e.VisitCount == t.n
+}
+
+// Parse parses a query. Relative ages in after: and before: are taken
+// back from now.
+func Parse(input string, now time.Time) (Expr, error) {
+	toks, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{input: input, toks: toks, now: now}
+	if p.peek().kind == tokEOF {
+		return nil, p.errorf(p.peek(), "empty query")
+	}
+	x, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+	return x, nil
+}
+
+type parser struct {
+	input string
+	toks  []token
+	i     int
+	now   time.Time
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) errorf(t token, format string, args ...any) *Error {
+	return &Error{Input: p.input, Pos: t.pos, Len: len(t.text), Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	alts := orExpr{x}
+	for p.peek().kind == tokOr {
+		p.next()
+		y, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		alts = append(alts, y)
+	}
+	if len(alts) == 1 {
+		return x, nil
+	}
+	return alts, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	all := andExpr{x}
+	for {
+		switch p.peek().kind {
+		case tokAnd:
+			p.next()
+		case tokWord, tokNot, tokLParen:
+			// adjacent terms are ANDed
+		default:
+			if len(all) == 1 {
+				return x, nil
+			}
+			return all, nil
+		}
+		y, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, y)
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.peek().kind == tokNot {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, p.errorf(t, "unclosed parenthesis")
+		}
+		p.next()
+		return x, nil
+	case tokWord:
+		return p.parseTerm(t)
+	case tokEOF:
+		return nil, p.errorf(t, "unexpected end of query, expected a term")
+	}
+	return nil, p.errorf(t, "unexpected %q, expected a term", t.text)
+}
+
+var (
+	visitsRE = regexp.MustCompile(`^(?i)visits(>=|<=|>|<|=)(.*)$`)
+	fieldRE  = regexp.MustCompile(`^([A-Za-z]+):(.*)$`)
+)
+
+func (p *parser) parseTerm(t token) (Expr, error) {
+	if m := visitsRE.FindStringSubmatch(t.text); m != nil {
+		n, err := strconv.Atoi(m[2])
+		if err != nil || n < 0 {
+			return nil, p.errorf(t, "invalid visit count %q", m[2])
+		}
+		return visitsTerm{op: m[1], n: n}, nil
+	}
+
+	m := fieldRE.FindStringSubmatch(t.text)
+	if m == nil || !isFieldName(m[1]) {
+		// A bare word, or a URL such as https://... or localhost:8080 used
+		// as a regex.
+		return p.regexTerm(t, browser.FieldURL, unquote(t.text))
+	}
+	name, value := strings.ToLower(m[1]), unquote(m[2])
+	if value == "" {
+		return nil, p.errorf(t, "missing value after %s:", name)
+	}
+	switch name {
+	case "domain":
+		d, err := domain.Normalize(value)
+		if err != nil {
+			return nil, p.errorf(t, "%v", err)
+		}
+		return domainTerm{d}, nil
+	case "after", "before":
+		ts, err := ParseTime(value, p.now)
+		if err != nil {
+			return nil, p.errorf(t, "%v", err)
+		}
+		return timeTerm{after: name == "after", t: ts}, nil
+	}
+	field, _ := browser.ParseField(name) // known by isFieldName
+	return p.regexTerm(t, field, value)
+}
+
+// HasTerms reports whether input uses query syntax — a field, domain,
+// time or visits term, AND, OR, NOT or parentheses — rather than being
+// plain words.
+func HasTerms(input string) bool {
+	toks, err := lex(input)
+	if err != nil {
+		return false
+	}
+	for _, t := range toks {
+		switch t.kind {
+		case tokAnd, tokOr, tokNot, tokLParen, tokRParen:
+			return true
+		case tokWord:
+			if visitsRE.MatchString(t.text) {
+				return true
+			}
+			if m := fieldRE.FindStringSubmatch(t.text); m != nil && isFieldName(m[1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isFieldName reports whether name, before a colon, names a field or a
+// keyword term rather than starting a URL.
+func isFieldName(name string) bool {
+	switch strings.ToLower(name) {
+	case "domain", "after", "before":
+		return true
+	}
+	_, err := browser.ParseField(name)
+	return err == nil
+}
+
+func (p *parser) regexTerm(t token, field browser.Field, pattern string) (Expr, error) {
+	m, err := browser.NewFieldMatch(field, pattern)
+	if err != nil {
+		return nil, p.errorf(t, "%v", err)
+	}
+	return fieldTerm{m}, nil
+}
+
+// ParseTime parses a date (2006-01-02, local midnight), an RFC 3339 time or
+// an age such as 30d, counted back from now.
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if age, err := ParseAge(s); err == nil {
+		return now.Add(-age), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use 2006-01-02, an RFC 3339 time or an age such as 30d", s)
+}
+
+// ParseAge parses an age such as "90d", "2w" or any time.ParseDuration
+// string like "36h".
+func ParseAge(s string) (time.Duration, error) {
+	unit := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if n := len(s); n > 1 {
+		if u, ok := unit[s[n-1]]; ok {
+			v, err := strconv.Atoi(s[:n-1])
+			if err == nil && v >= 0 {
+				return time.Duration(v) * u, nil
+			}
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q: use e.g. 12h, 30d or 2w", s)
+	}
+	return d, nil
+}
+
+// Compile parses a query into list options. Terms ANDed at the top level
+// become Fields, Domain, Since, Until and visit bounds, which backends push
+// into SQL where they can; anything else is left to Filter.
+func Compile(input string, now time.Time) (browser.ListOptions, error) {
+	x, err := Parse(input, now)
+	if err != nil {
+		return browser.ListOptions{}, err
+	}
+	return compile(x), nil
+}
+
+func compile(x Expr) browser.ListOptions {
+	conj, ok := x.(andExpr)
+	if !ok {
+		conj = andExpr{x}
+	}
+	var opts browser.ListOptions
+	var rest andExpr
+	for _, c := range conj {
+		switch t := c.(type) {
+		case fieldTerm:
+			opts.Fields = append(opts.Fields, t.m)
+			continue
+		case domainTerm:
+			if opts.Domain == "" {
+				opts.Domain = t.d
+				continue
+			}
+		case timeTerm:
+			if t.after && (opts.Since.IsZero() || t.t.After(opts.Since)) {
+				opts.Since = t.t
+				continue
+			}
+			// Until is inclusive, before: is not.
+			if until := t.t.Add(-time.Nanosecond); !t.after && (opts.Until.IsZero() || until.Before(opts.Until)) {
+				opts.Until = until
+				continue
+			}
+		case visitsTerm:
+			if pushVisits(&opts, t) {
+				continue
+			}
+		}
+		rest = append(rest, c)
+	}
+	if len(rest) > 0 {
+		opts.Filter = rest.Match
+	}
+	return opts
+}
+
+// pushVisits turns a visit count comparison into MinVisits or MaxVisits.
+// Bounds that would need a zero value, which means unset, are not pushed.
+func pushVisits(opts *browser.ListOptions, t visitsTerm) bool {
+	min, max := 0, 0
+	switch t.op {
+	case ">":
+		min = t.n + 1
+	case ">=":
+		min = t.n
+	case "<":
+		max = t.n - 1
+	case "<=":
+		max = t.n
+	case "=":
+		min, max = t.n, t.n
+	}
+	if (t.op != ">" && t.op != ">=" && max < 1) || (min > 0 && opts.MinVisits != 0) || (max > 0 && opts.MaxVisits != 0) {
+		return false
+	}
+	if min > 0 {
+		opts.MinVisits = min
+	}
+	if max > 0 {
+		opts.MaxVisits = max
+	}
+	return min > 0 || max > 0
+}
+
+// Merge returns list options selecting entries that match both a and b.
+func Merge(a, b browser.ListOptions) browser.ListOptions {
+	out := a
+	var extra []func(browser.HistoryEntry) bool
+	if b.Pattern != nil {
+		if out.Pattern == nil {
+			out.Pattern = b.Pattern
+		} else {
+			extra = append(extra, func(e browser.HistoryEntry) bool { return b.Pattern.MatchString(e.URL) })
+		}
+	}
+	if b.Domain != "" {
+		if out.Domain == "" {
+			out.Domain = b.Domain
+		} else {
+			extra = append(extra, domainTerm{b.Domain}.Match)
+		}
+	}
+	out.Fields = append(slices.Clip(out.Fields), b.Fields...)
+	if b.Since.After(out.Since) {
+		out.Since = b.Since
+	}
+	if !b.Until.IsZero() && (out.Until.IsZero() || b.Until.Before(out.Until)) {
+		out.Until = b.Until
+	}
+	out.MinVisits = max(out.MinVisits, b.MinVisits)
+	if b.MaxVisits > 0 && (out.MaxVisits == 0 || b.MaxVisits < out.MaxVisits) {
+		out.MaxVisits = b.MaxVisits
+	}
+	if b.Limit > 0 && (out.Limit == 0 || b.Limit < out.Limit) {
+		out.Limit = b.Limit
+	}
+	if b.Filter != nil {
+		extra = append(extra, b.Filter)
+	}
+	if len(extra) > 0 {
+		if a.Filter != nil {
+			extra = append(extra, a.Filter)
+		}
+		out.Filter = func(e browser.HistoryEntry) bool {
+			for _, f := range extra {
+				if !f(e) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	return out
+}
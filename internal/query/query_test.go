@@ -0,0 +1,183 @@
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+)
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"1d", 24 * time.Hour},
+		{"90d", 90 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"36h", 36 * time.Hour},
+	}
+	for _, tt := range tests {
+		got, err := ParseAge(tt.in)
+		if err != nil {
+			t.Fatalf("ParseAge(%q) error: %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseAge(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "d", "-1d", "soon"} {
+		if _, err := ParseAge(bad); err == nil {
+			t.Errorf("ParseAge(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseMatch(t *testing.T) {
+	gh := browser.HistoryEntry{URL: "https://github.com/odysa/histctl", Title: "histctl", VisitCount: 5, VisitTime: now.Add(-time.Hour)}
+	settings := browser.HistoryEntry{URL: "https://github.com/settings/profile", Title: "Settings", VisitCount: 9, VisitTime: now.Add(-time.Hour)}
+	mail := browser.HistoryEntry{URL: "https://mail.example.com/inbox", Title: "Inbox", VisitCount: 1, VisitTime: now.Add(-48 * time.Hour)}
+
+	tests := []struct {
+		query string
+		want  []bool // gh, settings, mail
+	}{
+		{"host:github.com AND NOT path:/settings AND after:2026-01-01 AND visits>3", []bool{true, false, false}},
+		{"github settings", []bool{false, true, false}},
+		{"title:inbox OR title:histctl", []bool{true, false, true}},
+		{"NOT (domain:github.com OR visits>=9)", []bool{false, false, true}},
+		{"before:1d", []bool{false, false, true}},
+		{"visits=1", []bool{false, false, true}},
+		{`title:"set tings" OR https://mail`, []bool{false, false, true}},
+		{"host:git AND (title:(hist|set))", []bool{true, true, false}},
+		{"localhost:8080 OR mailto:x OR com/odysa", []bool{true, false, false}}, // not fields
+	}
+	for _, tt := range tests {
+		x, err := Parse(tt.query, now)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.query, err)
+			continue
+		}
+		for i, e := range []browser.HistoryEntry{gh, settings, mail} {
+			if got := x.Match(e); got != tt.want[i] {
+				t.Errorf("Parse(%q).Match(%s) = %v, want %v", tt.query, e.URL, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query, msg string
+		column     int
+	}{
+		{"", "empty query", 1},
+		{"host:a AND", "unexpected end of query", 11},
+		{"host:a OR OR title:b", `unexpected "OR"`, 11},
+		{"(host:a", "unclosed parenthesis", 1},
+		{"host:a )", `unexpected ")"`, 8},
+		{"visits>many", "invalid visit count", 1},
+		{"after:yesterday", "invalid time", 1},
+		{"domain:com", "public suffix", 1},
+		{`title:"open`, "unterminated quote", 7},
+		{"host:(", "invalid host regex", 1},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.query, now)
+		qe, ok := err.(*Error)
+		if !ok {
+			t.Errorf("Parse(%q) error = %v, want *Error", tt.query, err)
+			continue
+		}
+		if !strings.Contains(qe.Msg, tt.msg) {
+			t.Errorf("Parse(%q) message = %q, want it to contain %q", tt.query, qe.Msg, tt.msg)
+		}
+		if col := qe.Pos + 1; col != tt.column {
+			t.Errorf("Parse(%q) column = %d, want %d", tt.query, col, tt.column)
+		}
+	}
+}
+
+func TestErrorContext(t *testing.T) {
+	_, err := Parse("host:a AND visits>x", now)
+	qe := err.(*Error)
+	want := "host:a AND visits>x\n           ^^^^^^^^"
+	if got := qe.Context(); got != want {
+		t.Errorf("Context() =\n%s\nwant\n%s", got, want)
+	}
+	if !strings.HasSuffix(qe.Error(), "at column 12") {
+		t.Errorf("Error() = %q, want the column", qe.Error())
+	}
+}
+
+func TestCompile(t *testing.T) {
+	opts, err := Compile("host:github.com AND NOT path:/settings AND after:2026-01-01 AND before:2026-02-01 AND visits>3", now)
+	if err != nil {
+		t.Fatalf("Compile() error: %v", err)
+	}
+	if len(opts.Fields) != 1 || opts.Fields[0].Field != browser.FieldHost {
+		t.Errorf("Compile() Fields = %+v, want the host matcher", opts.Fields)
+	}
+	if want := time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local); !opts.Since.Equal(want) {
+		t.Errorf("Compile() Since = %v, want %v", opts.Since, want)
+	}
+	if want := time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local).Add(-time.Nanosecond); !opts.Until.Equal(want) {
+		t.Errorf("Compile() Until = %v, want %v", opts.Until, want)
+	}
+	if opts.MinVisits != 4 || opts.MaxVisits != 0 {
+		t.Errorf("Compile() visits = %d..%d, want 4..", opts.MinVisits, opts.MaxVisits)
+	}
+	if opts.Filter == nil {
+		t.Fatal("Compile() should leave NOT path: to Filter")
+	}
+	if opts.Filter(browser.HistoryEntry{URL: "https://github.com/settings"}) {
+		t.Error("Filter should reject /settings")
+	}
+
+	opts, _ = Compile("visits<1 OR title:x", now)
+	if opts.MaxVisits != 0 || opts.Filter == nil {
+		t.Errorf("Compile(OR) = %+v, want everything in Filter", opts)
+	}
+	opts, _ = Compile("domain:example.com visits<=2", now)
+	if opts.Domain != "example.com" || opts.MaxVisits != 2 || opts.Filter != nil {
+		t.Errorf("Compile(domain, visits) = %+v, want Domain and MaxVisits only", opts)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, _ := Compile("domain:example.com after:2026-01-01 visits>=2", now)
+	b, _ := Compile("domain:mail.example.com after:2026-02-01 visits<=5", now)
+	m := Merge(a, b)
+	if m.Domain != "example.com" || m.MinVisits != 2 || m.MaxVisits != 5 {
+		t.Errorf("Merge() = %+v", m)
+	}
+	if want := time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local); !m.Since.Equal(want) {
+		t.Errorf("Merge() Since = %v, want the later %v", m.Since, want)
+	}
+	if m.Filter == nil || m.Filter(browser.HistoryEntry{URL: "https://www.example.com/"}) {
+		t.Error("Merge() should keep the second domain as a filter")
+	}
+}
+
+func TestHasTerms(t *testing.T) {
+	for in, want := range map[string]bool{
+		"go scheduler":         false,
+		"localhost:8080":       false,
+		"https://example.com/": false,
+		"Title:go":             true,
+		"domain:example.com":   true,
+		"visits>3":             true,
+		"go OR rust":           true,
+		"NOT github":           true,
+		"(go)":                 true,
+		`"unterminated`:        false,
+		"after:30d scheduler":  true,
+	} {
+		if got := HasTerms(in); got != want {
+			t.Errorf("HasTerms(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/odysa/histctl/internal/backup"
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/process"
+)
+
+// Action is one rule evaluated against one browser.
+type Action struct {
+	Rule    Rule
+	Browser browser.Browser
+	Options browser.DeleteOptions
+	Preview browser.DeleteResult
+	Err     error
+}
+
+// Plan is the set of deletes a rules run would perform.
+type Plan struct {
+	Actions []Action
+}
+
+// Matched returns the number of entries the plan would delete. A visit
+// matched by several rules counts once.
+func (p Plan) Matched() int {
+	seen := make(map[string]map[int64]bool)
+	n := 0
+	for _, a := range p.Actions {
+		name := a.Browser.Name()
+		if seen[name] == nil {
+			seen[name] = make(map[int64]bool)
+		}
+		for _, e := range a.Preview.Entries {
+			if !seen[name][e.VisitID] {
+				seen[name][e.VisitID] = true
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Evaluate previews every rule against the browsers it applies to.
+// Per-browser failures are recorded on the action rather than returned.
+func Evaluate(ctx context.Context, rules []Rule, browsers []browser.Browser, protect browser.Protector, now time.Time) Plan {
+	var plan Plan
+	for _, r := range rules {
+		for _, b := range browsers {
+			if !r.Applies(b.Name()) {
+				continue
+			}
+			a := Action{Rule: r, Browser: b, Options: r.Options(now, protect)}
+			preview := a.Options
+			preview.DryRun = true
+			a.Preview, a.Err = b.Delete(ctx, preview)
+			plan.Actions = append(plan.Actions, a)
+		}
+	}
+	return plan
+}
+
+// ApplyOptions controls how a plan is applied.
+type ApplyOptions struct {
+	NoBackup bool
+	// Rolling, if set, replaces one backup of that name per database (see
+	// backup.Rolling) instead of adding a timestamped one on every run.
+	Rolling string
+}
+
+// Outcome reports what Apply did to one browser.
+type Outcome struct {
+	Browser   string
+	Backup    string
+	Deleted   int
+	Protected int
+	Skipped   string // why the browser was left untouched, if it was
+	Err       error
+}
+
+// Apply runs the plan's actions browser by browser. A running browser is
+// skipped; otherwise its database is backed up once before the first
+// delete. Browsers with nothing to delete are left out of the outcomes.
+func Apply(ctx context.Context, plan Plan, opts ApplyOptions) []Outcome {
+	var order []browser.Browser
+	byBrowser := make(map[string][]Action)
+	for _, a := range plan.Actions {
+		if a.Err != nil || a.Preview.Matched == 0 {
+			continue
+		}
+		name := a.Browser.Name()
+		if _, ok := byBrowser[name]; !ok {
+			order = append(order, a.Browser)
+		}
+		byBrowser[name] = append(byBrowser[name], a)
+	}
+
+	var outcomes []Outcome
+	for _, b := range order {
+		outcomes = append(outcomes, applyBrowser(ctx, b, byBrowser[b.Name()], opts))
+	}
+	return outcomes
+}
+
+func applyBrowser(ctx context.Context, b browser.Browser, actions []Action, opts ApplyOptions) Outcome {
+	out := Outcome{Browser: b.Name()}
+
+	running, err := process.IsRunning(b.ProcessName())
+	if err != nil {
+		out.Err = fmt.Errorf("could not check if %s is running: %w", b.Name(), err)
+		return out
+	}
+	if running {
+		out.Skipped = "running"
+		return out
+	}
+
+	if !opts.NoBackup {
+		dbPath, err := b.DBPath()
+		if err != nil {
+			out.Err = err
+			return out
+		}
+		if opts.Rolling != "" {
+			out.Backup, err = backup.Rolling(dbPath, opts.Rolling)
+		} else {
+			out.Backup, err = backup.Create(dbPath)
+		}
+		if err != nil {
+			out.Err = fmt.Errorf("backup failed: %w", err)
+			return out
+		}
+	}
+
+	// Delete exactly the previewed visits, each once even if several rules
+	// matched it.
+	deleted := make(map[int64]bool)
+	for _, a := range actions {
+		var entries []browser.HistoryEntry
+		for _, e := range a.Preview.Entries {
+			if !deleted[e.VisitID] {
+				deleted[e.VisitID] = true
+				entries = append(entries, e)
+			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		confirmed := a.Options
+		confirmed.Entries = entries
+		result, err := b.Delete(ctx, confirmed)
+		if err != nil {
+			out.Err = fmt.Errorf("%s: %w", a.Rule.Name, err)
+			return out
+		}
+		out.Deleted += result.Deleted
+		out.Protected += len(result.Protected)
+	}
+	return out
+}
@@ -0,0 +1,181 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/config"
+	"github.com/odysa/histctl/internal/domain"
+	"github.com/odysa/histctl/internal/query"
+)
+
+// Rule describes history to delete. Patterns and domains select entries by
+// URL (any of them may match); Query, in the query package's language, and
+// MaxAge, which keeps only visits older than that age, narrow the selection
+// further. Empty Browsers means every browser.
+type Rule struct {
+	Name              string   `toml:"name"`
+	Patterns          []string `toml:"patterns"`
+	Domains           []string `toml:"domains"`
+	Browsers          []string `toml:"browsers"`
+	Query             string   `toml:"query"`
+	MaxAge            string   `toml:"max_age"`
+	IncludeBookmarked bool     `toml:"include_bookmarked"`
+
+	pattern *regexp.Regexp
+	domains []string // normalized
+	maxAge  time.Duration
+}
+
+// DefaultPath returns rules.toml inside the histctl config directory.
+func DefaultPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rules.toml"), nil
+}
+
+// Load reads the [[rule]] tables of the rules file at path. An empty path
+// means DefaultPath.
+func Load(path string) ([]Rule, error) {
+	if path == "" {
+		var err error
+		path, err = DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+	var file struct {
+		Rules []Rule `toml:"rule"`
+	}
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("no rules file at %s", path)
+		}
+		return nil, fmt.Errorf("load rules %s: %w", path, err)
+	}
+	for i := range file.Rules {
+		if err := file.Rules[i].compile(i); err != nil {
+			return nil, fmt.Errorf("load rules %s: %w", path, err)
+		}
+	}
+	return file.Rules, nil
+}
+
+func (r *Rule) compile(index int) error {
+	if r.Name == "" {
+		r.Name = fmt.Sprintf("rule %d", index+1)
+	}
+	if len(r.Patterns) == 0 && len(r.Domains) == 0 && r.Query == "" && r.MaxAge == "" {
+		return fmt.Errorf("%s matches all history; set patterns, domains, query or max_age", r.Name)
+	}
+	for _, name := range r.Browsers {
+		if !slices.Contains(browser.Names(), name) {
+			return fmt.Errorf("%s: unknown browser %q; supported: %v", r.Name, name, browser.Names())
+		}
+	}
+
+	var alts []string
+	for _, p := range r.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("%s: invalid pattern %q: %w", r.Name, p, err)
+		}
+		alts = append(alts, "(?:"+p+")")
+	}
+	if len(alts) > 0 {
+		r.pattern = regexp.MustCompile("(?i)" + strings.Join(alts, "|"))
+	}
+	for _, d := range r.Domains {
+		d, err := domain.Normalize(d)
+		if err != nil {
+			return fmt.Errorf("%s: %w", r.Name, err)
+		}
+		r.domains = append(r.domains, d)
+	}
+
+	if r.Query != "" {
+		if _, err := query.Parse(r.Query, time.Now()); err != nil {
+			return fmt.Errorf("%s: query: %w", r.Name, err)
+		}
+	}
+	if r.MaxAge != "" {
+		age, err := query.ParseAge(r.MaxAge)
+		if err != nil {
+			return fmt.Errorf("%s: max_age: %w", r.Name, err)
+		}
+		r.maxAge = age
+	}
+	return nil
+}
+
+// matchesURL reports whether e is selected by one of the rule's patterns
+// or domains.
+func (r Rule) matchesURL(e browser.HistoryEntry) bool {
+	if r.pattern != nil && r.pattern.MatchString(e.URL) {
+		return true
+	}
+	for _, d := range r.domains {
+		if domain.MatchURL(e.URL, d) {
+			return true
+		}
+	}
+	return false
+}
+
+// Applies reports whether the rule targets the named browser.
+func (r Rule) Applies(name string) bool {
+	return len(r.Browsers) == 0 || slices.Contains(r.Browsers, name)
+}
+
+// Options returns the delete options selecting this rule's entries as of now.
+func (r Rule) Options(now time.Time, protect browser.Protector) browser.DeleteOptions {
+	opts := browser.DeleteOptions{
+		ListOptions:       browser.ListOptions{Pattern: r.pattern},
+		IncludeBookmarked: r.IncludeBookmarked,
+		Protect:           protect,
+	}
+	if len(r.domains) > 0 {
+		// Patterns and domains are alternatives, so both go in one filter.
+		opts.ListOptions = browser.ListOptions{Filter: r.matchesURL}
+	}
+	if r.Query != "" {
+		// The query was checked by Load; only relative ages depend on now.
+		q, _ := query.Compile(r.Query, now)
+		opts.ListOptions = query.Merge(opts.ListOptions, q)
+	}
+	if r.maxAge > 0 {
+		opts.ListOptions = query.Merge(opts.ListOptions, browser.ListOptions{Until: now.Add(-r.maxAge)})
+	}
+	return opts
+}
+
+// String describes the rule in one line for plan output.
+func (r Rule) String() string {
+	var parts []string
+	if len(r.Patterns) > 0 {
+		parts = append(parts, "patterns "+strings.Join(r.Patterns, ", "))
+	}
+	if len(r.Domains) > 0 {
+		parts = append(parts, "domains "+strings.Join(r.Domains, ", "))
+	}
+	if r.Query != "" {
+		parts = append(parts, "query "+r.Query)
+	}
+	if r.MaxAge != "" {
+		parts = append(parts, "older than "+r.MaxAge)
+	}
+	if r.IncludeBookmarked {
+		parts = append(parts, "including bookmarked")
+	}
+	return fmt.Sprintf("%s (%s)", r.Name, strings.Join(parts, "; "))
+}
@@ -0,0 +1,227 @@
+package rules
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+)
+
+func writeRules(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write rules: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeRules(t, `
+[[rule]]
+name = "bank"
+domains = ["bank.com"]
+max_age = "1d"
+
+[[rule]]
+patterns = ['\?utm_']
+browsers = ["firefox"]
+
+[[rule]]
+name = "stale"
+query = "host:example AND visits<2"
+`)
+	rules, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("Load() returned %d rules, want 3", len(rules))
+	}
+	if rules[1].Name != "rule 2" {
+		t.Errorf("unnamed rule Name = %q, want %q", rules[1].Name, "rule 2")
+	}
+	if rules[1].Applies("chrome") || !rules[1].Applies("firefox") {
+		t.Error("rule 2 should apply to firefox only")
+	}
+
+	now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	opts := rules[0].Options(now, nil)
+	if want := now.Add(-24 * time.Hour); !opts.Until.Equal(want) {
+		t.Errorf("Options().Until = %v, want %v", opts.Until, want)
+	}
+	for url, want := range map[string]bool{
+		"https://bank.com/login":               true,
+		"https://online.BANK.com":              true,
+		"http://bank.com:8443/x":               true,
+		"https://notbank.com/":                 false,
+		"https://example.com/?next=bank.com/x": false,
+	} {
+		if got := opts.Matches(browser.HistoryEntry{URL: url}); got != want {
+			t.Errorf("bank rule matches %q = %v, want %v", url, got, want)
+		}
+	}
+
+	opts = rules[2].Options(now, nil)
+	if len(opts.Fields) != 1 || opts.MaxVisits != 1 {
+		t.Errorf("query rule Options() = %+v, want a host field and MaxVisits 1", opts.ListOptions)
+	}
+}
+
+func TestLoadInvalidQuery(t *testing.T) {
+	path := writeRules(t, "[[rule]]\nname = \"bad\"\nquery = \"host:a AND\"\n")
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "bad: query:") {
+		t.Fatalf("Load() error = %v, want a query error naming the rule", err)
+	}
+}
+
+func TestLoadRejectsMatchAll(t *testing.T) {
+	path := writeRules(t, "[[rule]]\nname = \"everything\"\n")
+	if _, err := Load(path); err == nil {
+		t.Fatal("Load() should reject a rule without patterns, domains or max_age")
+	}
+}
+
+func TestDomains(t *testing.T) {
+	path := writeRules(t, "[[rule]]\ndomains = [\"Tracker.example\"]\npatterns = ['\\?utm_']\n")
+	rules, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	opts := rules[0].Options(time.Now(), nil)
+	for url, want := range map[string]bool{
+		"https://ads.tracker.example/x": true,
+		"https://example.com/?utm_x=1":  true, // patterns and domains are alternatives
+		"https://example.com/":          false,
+	} {
+		if got := opts.Matches(browser.HistoryEntry{URL: url}); got != want {
+			t.Errorf("rule matches %q = %v, want %v", url, got, want)
+		}
+	}
+
+	path = writeRules(t, "[[rule]]\ndomains = [\"co.uk\"]\n")
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "public suffix") {
+		t.Errorf("Load() error = %v, want a public suffix error", err)
+	}
+}
+
+func TestLoadUnknownBrowser(t *testing.T) {
+	path := writeRules(t, "[[rule]]\nmax_age = \"1d\"\nbrowsers = [\"netscape\"]\n")
+	if _, err := Load(path); err == nil {
+		t.Fatal("Load() should reject an unknown browser")
+	}
+}
+
+// fakeBrowser records delete calls and matches a fixed number of visits,
+// or the entries it is given.
+type fakeBrowser struct {
+	name    string
+	dbPath  string
+	matched int
+	calls   []browser.DeleteOptions
+}
+
+func (f *fakeBrowser) Name() string            { return f.name }
+func (f *fakeBrowser) DBPath() (string, error) { return f.dbPath, nil }
+func (f *fakeBrowser) ProcessName() string     { return "histctl-test-no-such-process" }
+func (f *fakeBrowser) List(ctx context.Context, opts browser.ListOptions) ([]browser.HistoryEntry, error) {
+	return nil, nil
+}
+func (f *fakeBrowser) Delete(ctx context.Context, opts browser.DeleteOptions) (browser.DeleteResult, error) {
+	f.calls = append(f.calls, opts)
+	entries := opts.Entries
+	if entries == nil {
+		for i := range f.matched {
+			entries = append(entries, browser.HistoryEntry{Browser: f.name, VisitID: int64(i + 1)})
+		}
+	}
+	result := browser.DeleteResult{Matched: len(entries), Entries: entries}
+	if !opts.DryRun {
+		result.Deleted = len(entries)
+	}
+	return result, nil
+}
+
+func TestEvaluateAndApply(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "History")
+	if err := os.WriteFile(dbPath, []byte("db"), 0644); err != nil {
+		t.Fatalf("write db: %v", err)
+	}
+	chrome := &fakeBrowser{name: "chrome", dbPath: dbPath, matched: 3}
+	firefox := &fakeBrowser{name: "firefox", dbPath: dbPath}
+
+	rules := []Rule{
+		{Name: "old", MaxAge: "30d"},
+		{Name: "ff", MaxAge: "1d", Browsers: []string{"firefox"}},
+	}
+	for i := range rules {
+		if err := rules[i].compile(i); err != nil {
+			t.Fatalf("compile: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+	plan := Evaluate(ctx, rules, []browser.Browser{chrome, firefox}, nil, time.Now())
+	if len(plan.Actions) != 3 {
+		t.Fatalf("Evaluate() produced %d actions, want 3", len(plan.Actions))
+	}
+	if plan.Matched() != 3 {
+		t.Errorf("plan.Matched() = %d, want 3", plan.Matched())
+	}
+
+	outcomes := Apply(ctx, plan, ApplyOptions{})
+	if len(outcomes) != 1 {
+		t.Fatalf("Apply() returned %d outcomes, want 1 (firefox has nothing to delete)", len(outcomes))
+	}
+	out := outcomes[0]
+	if out.Err != nil {
+		t.Fatalf("Apply() error: %v", out.Err)
+	}
+	if out.Browser != "chrome" || out.Deleted != 3 || out.Backup == "" {
+		t.Errorf("Apply() outcome = %+v, want 3 deleted from chrome with a backup", out)
+	}
+	if last := chrome.calls[len(chrome.calls)-1]; last.DryRun {
+		t.Error("Apply() should delete for real")
+	}
+
+	// A rolling backup is replaced on every run.
+	for range 2 {
+		out = Apply(ctx, plan, ApplyOptions{Rolling: "daemon"})[0]
+		if want := dbPath + ".daemon.bak"; out.Backup != want {
+			t.Errorf("Apply(Rolling) backup = %q, want %q", out.Backup, want)
+		}
+	}
+}
+
+func TestOverlappingRules(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "History")
+	if err := os.WriteFile(dbPath, []byte("db"), 0644); err != nil {
+		t.Fatalf("write db: %v", err)
+	}
+	chrome := &fakeBrowser{name: "chrome", dbPath: dbPath, matched: 3}
+	rules := []Rule{{Name: "a", MaxAge: "30d"}, {Name: "b", MaxAge: "60d"}}
+	for i := range rules {
+		if err := rules[i].compile(i); err != nil {
+			t.Fatalf("compile: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+	plan := Evaluate(ctx, rules, []browser.Browser{chrome}, nil, time.Now())
+	if plan.Matched() != 3 {
+		t.Errorf("plan.Matched() = %d, want 3 (both rules match the same visits)", plan.Matched())
+	}
+	out := Apply(ctx, plan, ApplyOptions{NoBackup: true})[0]
+	if out.Err != nil || out.Deleted != 3 {
+		t.Errorf("Apply() outcome = %+v, want 3 deleted", out)
+	}
+	last := chrome.calls[len(chrome.calls)-1]
+	if last.DryRun || len(last.Entries) != 3 {
+		t.Errorf("Apply() deleted %+v, want the 3 previewed visits once", last)
+	}
+}
@@ -0,0 +1,102 @@
+// Package scan searches raw file bytes for URL strings, to find history
+// that survives in free pages, WAL frames, backups and caches after it was
+// deleted.
+package scan
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/odysa/histctl/internal/session"
+)
+
+// urlRE matches a URL stored as UTF-8 text. It stops at bytes that cannot
+// appear in a URL, which also ends it at SQLite record boundaries. There is
+// no word boundary before the scheme because records pack text columns
+// back to back.
+var urlRE = regexp.MustCompile(`(?i)(?:https?|ftp|file)://[^\x00-\x20\x7f-\xff"'<>\\^` + "`" + `{|}]+`)
+
+// Hit is one occurrence of a matching URL in a file.
+type Hit struct {
+	Path   string `json:"path"`
+	Offset int64  `json:"offset"`
+	URL    string `json:"url"`
+}
+
+// File returns every URL in the file at path that pattern matches, in
+// file order. A nil pattern matches all URLs. Firefox mozlz4 files are
+// decompressed first, and their offsets are in the decompressed data; one
+// that does not decompress is scanned as it is.
+func File(path string, pattern *regexp.Regexp) ([]Hit, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("scan %s: %w", path, err)
+	}
+	if session.IsMozLz4(data) {
+		if raw, err := session.DecodeMozLz4(data); err == nil {
+			data = raw
+		}
+	}
+	var hits []Hit
+	for _, loc := range urlRE.FindAllIndex(data, -1) {
+		u := string(data[loc[0]:loc[1]])
+		if pattern != nil && !pattern.MatchString(u) {
+			continue
+		}
+		hits = append(hits, Hit{Path: path, Offset: int64(loc[0]), URL: u})
+	}
+	return hits, nil
+}
+
+// Files scans each path in turn. Files that vanished since they were listed
+// are skipped; other errors stop the scan and are returned with the hits so
+// far.
+func Files(paths []string, pattern *regexp.Regexp) ([]Hit, error) {
+	var hits []Hit
+	for _, p := range paths {
+		h, err := File(p, pattern)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return hits, err
+		}
+		hits = append(hits, h...)
+	}
+	return hits, nil
+}
+
+// Live returns the URL of live history that the hit u is, and whether
+// there is one. live maps each live URL to its title. A hit is a live URL
+// if it is the URL itself, the URL followed by a byte that cannot be part
+// of a URL, or the URL run into the start of its own title, as stored in
+// a database record. A longer URL that merely starts with a live one, such
+// as a deleted page of a live site, is not live.
+func Live(u string, live map[string]string) (string, bool) {
+	if _, ok := live[u]; ok {
+		return u, true
+	}
+	best := ""
+	for l, title := range live {
+		if len(l) <= len(best) || len(l) >= len(u) || u[:len(l)] != l {
+			continue
+		}
+		rest := u[len(l):]
+		if !urlByte(rest[0]) || strings.HasPrefix(title, rest) {
+			best = l
+		}
+	}
+	if best == "" {
+		return u, false
+	}
+	return best, true
+}
+
+// urlByte reports whether c can be part of a URL matched by urlRE.
+func urlByte(c byte) bool {
+	return c > 0x20 && c < 0x7f && !strings.ContainsRune("\"'<>\\^`{|}", rune(c))
+}
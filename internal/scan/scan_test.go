@@ -0,0 +1,111 @@
+package scan
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/pierrec/lz4/v4"
+)
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "History-wal")
+	data := "\x00\x01https://github.com/odysa\x00junkhttps://example.com/a?b=1\x12http://GITHUB.com\"tail"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	hits, err := File(path, regexp.MustCompile("(?i)github"))
+	if err != nil {
+		t.Fatalf("File() error: %v", err)
+	}
+	want := []Hit{
+		{Path: path, Offset: 2, URL: "https://github.com/odysa"},
+		{Path: path, Offset: 57, URL: "http://GITHUB.com"},
+	}
+	if len(hits) != len(want) {
+		t.Fatalf("File() = %+v, want %+v", hits, want)
+	}
+	for i := range want {
+		if hits[i] != want[i] {
+			t.Errorf("File()[%d] = %+v, want %+v", i, hits[i], want[i])
+		}
+	}
+
+	all, err := File(path, nil)
+	if err != nil {
+		t.Fatalf("File(nil) error: %v", err)
+	}
+	if len(all) != 3 || all[1].URL != "https://example.com/a?b=1" {
+		t.Errorf("File(nil) = %+v, want 3 URLs", all)
+	}
+}
+
+func TestFileMozLz4(t *testing.T) {
+	state := []byte(`{"windows":[{"tabs":[{"entries":[{"url":"https://github.com/odysa","title":"odysa"}]}]}]}`)
+	block := make([]byte, lz4.CompressBlockBound(len(state)))
+	var c lz4.Compressor
+	n, err := c.CompressBlock(state, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := binary.LittleEndian.AppendUint32([]byte("mozLz40\x00"), uint32(len(state)))
+	data = append(data, block[:n]...)
+	path := filepath.Join(t.TempDir(), "sessionstore.jsonlz4")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	hits, err := File(path, nil)
+	if err != nil {
+		t.Fatalf("File() error: %v", err)
+	}
+	want := Hit{Path: path, Offset: 41, URL: "https://github.com/odysa"}
+	if len(hits) != 1 || hits[0] != want {
+		t.Errorf("File() = %+v, want %+v", hits, want)
+	}
+}
+
+func TestFilesSkipsMissing(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Favicons")
+	if err := os.WriteFile(path, []byte("https://example.com"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	hits, err := Files([]string{filepath.Join(dir, "gone"), path}, nil)
+	if err != nil {
+		t.Fatalf("Files() error: %v", err)
+	}
+	if len(hits) != 1 || hits[0].Path != path {
+		t.Errorf("Files() = %+v, want one hit in %s", hits, path)
+	}
+}
+
+func TestLive(t *testing.T) {
+	live := map[string]string{
+		"https://bank.com":         "Bank",
+		"https://github.com/odysa": "odysa/histctl",
+	}
+	tests := []struct {
+		hit  string
+		want string
+		ok   bool
+	}{
+		{"https://bank.com", "https://bank.com", true},
+		{"https://bank.comBank", "https://bank.com", true}, // run into its title
+		{"https://bank.com/account", "https://bank.com/account", false},
+		{"https://bank.com.evil.example", "https://bank.com.evil.example", false},
+		{"https://github.com/odysaodysa/histctl", "https://github.com/odysa", true},
+		{"https://github.com/odysa/histctl", "https://github.com/odysa/histctl", false},
+		{"https://example.com", "https://example.com", false},
+	}
+	for _, tt := range tests {
+		got, ok := Live(tt.hit, live)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Live(%q) = %q, %v; want %q, %v", tt.hit, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package session
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf16"
+)
+
+// snssMagic starts a Chrome session file. It is followed by an int32
+// version and a sequence of commands, each a uint16 size, a command id and
+// a base::Pickle payload.
+const snssMagic = "SNSS"
+
+// Navigation command ids. Session_* files are written by the session
+// service, Tabs_* files by the tab restore service, which numbers its
+// commands differently.
+const (
+	sessionUpdateTabNavigation    = 6
+	tabRestoreUpdateTabNavigation = 1
+)
+
+// isTabRestore reports whether a session file name belongs to the tab
+// restore service ("recently closed"), including the pre-M88 names.
+func isTabRestore(name string) bool {
+	return strings.HasPrefix(name, "Tabs_") || name == "Current Tabs" || name == "Last Tabs"
+}
+
+type snssCommand struct {
+	id      byte
+	payload []byte
+}
+
+// snssFile is a decoded SNSS file. Commands other than navigations are kept
+// verbatim.
+type snssFile struct {
+	header   []byte
+	commands []snssCommand
+	navID    byte
+}
+
+func parseSNSS(data []byte, tabRestore bool) (*snssFile, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("truncated SNSS header")
+	}
+	f := &snssFile{header: data[:8], navID: sessionUpdateTabNavigation}
+	if tabRestore {
+		f.navID = tabRestoreUpdateTabNavigation
+	}
+	rest := data[8:]
+	for len(rest) >= 2 {
+		size := int(binary.LittleEndian.Uint16(rest))
+		rest = rest[2:]
+		if size == 0 || size > len(rest) {
+			// Chrome stops at a truncated command as well.
+			break
+		}
+		f.commands = append(f.commands, snssCommand{id: rest[0], payload: rest[1:size]})
+		rest = rest[size:]
+	}
+	return f, nil
+}
+
+func (f *snssFile) entries() []Entry {
+	var out []Entry
+	for _, c := range f.commands {
+		if e, ok := f.navigation(c); ok {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// remove drops matching navigation commands. Chrome skips tabs whose
+// navigations are all gone when it restores them.
+func (f *snssFile) remove(match func(Entry) bool) int {
+	kept := f.commands[:0]
+	n := 0
+	for _, c := range f.commands {
+		if e, ok := f.navigation(c); ok && match(e) {
+			n++
+			continue
+		}
+		kept = append(kept, c)
+	}
+	f.commands = kept
+	return n
+}
+
+func (f *snssFile) encode() ([]byte, error) {
+	out := append([]byte{}, f.header...)
+	for _, c := range f.commands {
+		size := len(c.payload) + 1
+		if size > 0xffff {
+			return nil, fmt.Errorf("command %d too large", c.id)
+		}
+		out = binary.LittleEndian.AppendUint16(out, uint16(size))
+		out = append(out, c.id)
+		out = append(out, c.payload...)
+	}
+	return out, nil
+}
+
+// navigation decodes a serialized navigation entry: the pickle payload
+// size, tab id, navigation index, URL and UTF-16 title.
+func (f *snssFile) navigation(c snssCommand) (Entry, bool) {
+	if c.id != f.navID {
+		return Entry{}, false
+	}
+	p := pickle{data: c.payload}
+	p.int32() // payload size
+	p.int32() // tab id
+	p.int32() // index
+	u := string(p.bytes(int(p.int32())))
+	title := p.string16()
+	if p.err || !isURL(u) {
+		return Entry{}, false
+	}
+	return Entry{URL: u, Title: title}, true
+}
+
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != ""
+}
+
+// pickle reads base::Pickle fields, which are 4-byte aligned.
+type pickle struct {
+	data []byte
+	off  int
+	err  bool
+}
+
+func (p *pickle) bytes(n int) []byte {
+	if p.err || n < 0 || p.off+n > len(p.data) {
+		p.err = true
+		return nil
+	}
+	b := p.data[p.off : p.off+n]
+	p.off = min(p.off+(n+3)&^3, len(p.data))
+	return b
+}
+
+func (p *pickle) int32() int32 {
+	b := p.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return int32(binary.LittleEndian.Uint32(b))
+}
+
+func (p *pickle) string16() string {
+	n := int(p.int32())
+	b := p.bytes(n * 2)
+	if b == nil {
+		return ""
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[i*2:])
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,239 @@
+package session
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pierrec/lz4/v4"
+)
+
+// mozlz4Magic starts a Firefox mozlz4 file. It is followed by the
+// decompressed size as a little-endian uint32 and a single LZ4 block.
+const mozlz4Magic = "mozLz40\x00"
+
+// IsMozLz4 reports whether data starts like a mozlz4 file.
+func IsMozLz4(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(mozlz4Magic))
+}
+
+// maxLz4Ratio bounds how much an LZ4 block can expand, so a corrupt size
+// in the header cannot make DecodeMozLz4 allocate gigabytes.
+const maxLz4Ratio = 255
+
+// DecodeMozLz4 returns the decompressed contents of a mozlz4 file, the
+// format of Firefox session files and bookmark backups.
+func DecodeMozLz4(data []byte) ([]byte, error) {
+	hdr := len(mozlz4Magic) + 4
+	if len(data) < hdr || !IsMozLz4(data) {
+		return nil, fmt.Errorf("not a mozlz4 file")
+	}
+	size := int64(binary.LittleEndian.Uint32(data[len(mozlz4Magic):]))
+	if size > maxLz4Ratio*int64(len(data)-hdr) {
+		return nil, fmt.Errorf("mozlz4 size %d is too large for %d bytes of data", size, len(data))
+	}
+	out := make([]byte, size)
+	n, err := lz4.UncompressBlock(data[hdr:], out)
+	if err != nil {
+		return nil, fmt.Errorf("decompress mozlz4: %w", err)
+	}
+	return out[:n], nil
+}
+
+// encodeMozLz4 compresses data into a mozlz4 file.
+func encodeMozLz4(data []byte) ([]byte, error) {
+	hdr := len(mozlz4Magic) + 4
+	out := make([]byte, hdr+lz4.CompressBlockBound(len(data)))
+	copy(out, mozlz4Magic)
+	binary.LittleEndian.PutUint32(out[len(mozlz4Magic):], uint32(len(data)))
+	var c lz4.Compressor
+	n, err := c.CompressBlock(data, out[hdr:])
+	if err != nil {
+		return nil, fmt.Errorf("compress mozlz4: %w", err)
+	}
+	return out[:hdr+n], nil
+}
+
+// firefoxSession is a decoded sessionstore file. The JSON is kept generic
+// so fields histctl does not know about survive a rewrite.
+type firefoxSession struct {
+	state any
+}
+
+func parseFirefox(data []byte) (*firefoxSession, error) {
+	raw, err := DecodeMozLz4(data)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var state any
+	if err := dec.Decode(&state); err != nil {
+		return nil, fmt.Errorf("parse session JSON: %w", err)
+	}
+	return &firefoxSession{state: state}, nil
+}
+
+func (s *firefoxSession) entries() []Entry {
+	var out []Entry
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if entries, ok := v["entries"].([]any); ok {
+				collectFirefoxEntries(entries, &out)
+			}
+			for k, child := range v {
+				if k != "entries" {
+					walk(child)
+				}
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(s.state)
+	return out
+}
+
+// collectFirefoxEntries appends the history entries of a tab, including
+// subframe children.
+func collectFirefoxEntries(entries []any, out *[]Entry) {
+	for _, e := range entries {
+		m, ok := e.(map[string]any)
+		if !ok {
+			continue
+		}
+		if u, ok := m["url"].(string); ok {
+			title, _ := m["title"].(string)
+			*out = append(*out, Entry{URL: u, Title: title})
+		}
+		if children, ok := m["children"].([]any); ok {
+			collectFirefoxEntries(children, out)
+		}
+	}
+}
+
+func (s *firefoxSession) remove(match func(Entry) bool) int {
+	return pruneFirefox(s.state, match)
+}
+
+func (s *firefoxSession) encode() ([]byte, error) {
+	raw, err := json.Marshal(s.state)
+	if err != nil {
+		return nil, err
+	}
+	return encodeMozLz4(raw)
+}
+
+// pruneFirefox removes matching entries below v. Tabs left without entries
+// and windows left without tabs are dropped, and the 1-based selection
+// indexes are kept in range.
+func pruneFirefox(v any, match func(Entry) bool) int {
+	n := 0
+	switch v := v.(type) {
+	case map[string]any:
+		if entries, ok := v["entries"].([]any); ok {
+			kept, removed := pruneFirefoxEntries(entries, match)
+			v["entries"] = kept
+			clampIndex(v, "index", len(kept))
+			n += removed
+		}
+		for k, child := range v {
+			if k != "entries" {
+				n += pruneFirefox(child, match)
+			}
+		}
+		for _, k := range []string{"tabs", "_closedTabs"} {
+			if tabs, ok := v[k].([]any); ok {
+				v[k] = dropEmpty(tabs, emptyTab)
+			}
+		}
+		for _, k := range []string{"windows", "_closedWindows"} {
+			if windows, ok := v[k].([]any); ok {
+				v[k] = dropEmpty(windows, emptyWindow)
+			}
+		}
+		if tabs, ok := v["tabs"].([]any); ok {
+			clampIndex(v, "selected", len(tabs))
+		}
+		if windows, ok := v["windows"].([]any); ok {
+			clampIndex(v, "selectedWindow", len(windows))
+		}
+	case []any:
+		for _, child := range v {
+			n += pruneFirefox(child, match)
+		}
+	}
+	return n
+}
+
+func pruneFirefoxEntries(entries []any, match func(Entry) bool) ([]any, int) {
+	kept := make([]any, 0, len(entries))
+	removed := 0
+	for _, e := range entries {
+		m, ok := e.(map[string]any)
+		if !ok {
+			kept = append(kept, e)
+			continue
+		}
+		if u, ok := m["url"].(string); ok {
+			title, _ := m["title"].(string)
+			if match(Entry{URL: u, Title: title}) {
+				removed++
+				continue
+			}
+		}
+		if children, ok := m["children"].([]any); ok {
+			var n int
+			m["children"], n = pruneFirefoxEntries(children, match)
+			removed += n
+		}
+		kept = append(kept, m)
+	}
+	return kept, removed
+}
+
+func dropEmpty(items []any, empty func(map[string]any) bool) []any {
+	kept := make([]any, 0, len(items))
+	for _, it := range items {
+		if m, ok := it.(map[string]any); ok && empty(m) {
+			continue
+		}
+		kept = append(kept, it)
+	}
+	return kept
+}
+
+// emptyTab reports whether an open tab, or the state of a closed one, has
+// lost all its entries.
+func emptyTab(tab map[string]any) bool {
+	if state, ok := tab["state"].(map[string]any); ok {
+		tab = state
+	}
+	entries, ok := tab["entries"].([]any)
+	return ok && len(entries) == 0
+}
+
+func emptyWindow(w map[string]any) bool {
+	tabs, ok := w["tabs"].([]any)
+	return ok && len(tabs) == 0
+}
+
+// clampIndex keeps the 1-based index stored under key within 1..n.
+func clampIndex(m map[string]any, key string, n int) {
+	num, ok := m[key].(json.Number)
+	if !ok || n == 0 {
+		return
+	}
+	i, err := num.Int64()
+	if err != nil {
+		return
+	}
+	if i > int64(n) {
+		m[key] = json.Number(fmt.Sprint(n))
+	}
+}
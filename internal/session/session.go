@@ -0,0 +1,131 @@
+// Package session reads and edits browser session files, which keep the
+// URLs of open and recently closed tabs independently of history: Firefox
+// mozlz4-compressed JSON and Chrome's SNSS command log.
+package session
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ErrUnknownFormat is returned for files that are neither mozlz4 nor SNSS.
+var ErrUnknownFormat = errors.New("unknown session file format")
+
+// Entry is a navigation recorded in a session file.
+type Entry struct {
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+}
+
+// URLMatcher returns a match function selecting entries whose URL matches
+// pattern.
+func URLMatcher(pattern *regexp.Regexp) func(Entry) bool {
+	return func(e Entry) bool { return pattern.MatchString(e.URL) }
+}
+
+// format decodes and re-encodes one kind of session file.
+type format interface {
+	entries() []Entry
+	// remove drops the navigations match selects and returns how many were
+	// dropped.
+	remove(match func(Entry) bool) int
+	encode() ([]byte, error)
+}
+
+func parse(path string, data []byte) (format, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte(mozlz4Magic)):
+		return parseFirefox(data)
+	case bytes.HasPrefix(data, []byte(snssMagic)):
+		return parseSNSS(data, isTabRestore(filepath.Base(path)))
+	}
+	return nil, ErrUnknownFormat
+}
+
+func load(path string) (format, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parse(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Read returns the navigations recorded in the session file at path.
+func Read(path string) ([]Entry, error) {
+	f, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+	return f.entries(), nil
+}
+
+// Match returns the entries of the session file at path that match selects.
+func Match(path string, match func(Entry) bool) ([]Entry, error) {
+	all, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+	var matched []Entry
+	for _, e := range all {
+		if match(e) {
+			matched = append(matched, e)
+		}
+	}
+	return matched, nil
+}
+
+// Remove rewrites the session file at path without the navigations match
+// selects. Tabs left without navigations are dropped. It returns the number
+// of navigations removed; the file is untouched when nothing matched.
+func Remove(path string, match func(Entry) bool) (int, error) {
+	f, err := load(path)
+	if err != nil {
+		return 0, err
+	}
+	n := f.remove(match)
+	if n == 0 {
+		return 0, nil
+	}
+	data, err := f.encode()
+	if err != nil {
+		return 0, fmt.Errorf("encode %s: %w", path, err)
+	}
+	if err := writeFile(path, data); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// writeFile replaces path with data through a temporary file in the same
+// directory, keeping the original permissions.
+func writeFile(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
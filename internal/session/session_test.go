@@ -0,0 +1,221 @@
+package session
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"unicode/utf16"
+)
+
+// navPayload builds a navigation pickle as Chrome serializes it.
+func navPayload(tabID int32, u, title string) []byte {
+	pad := func(b []byte) []byte {
+		for len(b)%4 != 0 {
+			b = append(b, 0)
+		}
+		return b
+	}
+	var body []byte
+	body = binary.LittleEndian.AppendUint32(body, uint32(tabID))
+	body = binary.LittleEndian.AppendUint32(body, 0)
+	body = binary.LittleEndian.AppendUint32(body, uint32(len(u)))
+	body = pad(append(body, u...))
+	units := utf16.Encode([]rune(title))
+	body = binary.LittleEndian.AppendUint32(body, uint32(len(units)))
+	for _, c := range units {
+		body = binary.LittleEndian.AppendUint16(body, c)
+	}
+	body = pad(body)
+	return append(binary.LittleEndian.AppendUint32(nil, uint32(len(body))), body...)
+}
+
+func writeSNSS(t *testing.T, path string, cmds []snssCommand) {
+	t.Helper()
+	f := &snssFile{header: []byte("SNSS\x03\x00\x00\x00"), commands: cmds}
+	data, err := f.encode()
+	if err != nil {
+		t.Fatalf("encode SNSS: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("write SNSS: %v", err)
+	}
+}
+
+func TestChromeSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Session_13370000000000000")
+	writeSNSS(t, path, []snssCommand{
+		{id: 0, payload: []byte{1, 2, 3, 4}}, // unrelated command
+		{id: sessionUpdateTabNavigation, payload: navPayload(1, "https://example.com/", "Example")},
+		{id: sessionUpdateTabNavigation, payload: navPayload(1, "https://github.com/odysa", "GitHub ✓")},
+		{id: tabRestoreUpdateTabNavigation, payload: navPayload(2, "https://github.com/", "wrong id")},
+	})
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if len(entries) != 2 || entries[1] != (Entry{URL: "https://github.com/odysa", Title: "GitHub ✓"}) {
+		t.Fatalf("Read() = %+v, want example.com and github.com navigations", entries)
+	}
+
+	n, err := Remove(path, URLMatcher(regexp.MustCompile("github")))
+	if err != nil {
+		t.Fatalf("Remove() error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Remove() = %d, want 1", n)
+	}
+	entries, _ = Read(path)
+	if len(entries) != 1 || entries[0].URL != "https://example.com/" {
+		t.Errorf("Read() after Remove = %+v, want only example.com", entries)
+	}
+	data, _ := os.ReadFile(path)
+	f, _ := parseSNSS(data, false)
+	if len(f.commands) != 3 {
+		t.Errorf("Remove() kept %d commands, want 3", len(f.commands))
+	}
+}
+
+func TestChromeTabRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Tabs_13370000000000000")
+	writeSNSS(t, path, []snssCommand{
+		{id: tabRestoreUpdateTabNavigation, payload: navPayload(7, "https://closed.example/", "Closed")},
+	})
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].URL != "https://closed.example/" {
+		t.Errorf("Read() = %+v, want the closed tab", entries)
+	}
+}
+
+const firefoxState = `{
+	"version": ["sessionrestore", 1],
+	"windows": [{
+		"selected": 2,
+		"tabs": [
+			{"entries": [{"url": "https://example.com/", "title": "Example"}], "index": 1},
+			{"entries": [
+				{"url": "https://golang.org/", "title": "Go"},
+				{"url": "https://github.com/odysa", "title": "GitHub", "children": [{"url": "https://github.com/frame"}]}
+			], "index": 2}
+		],
+		"_closedTabs": [
+			{"state": {"entries": [{"url": "https://github.com/closed", "title": "Closed"}]}, "title": "Closed"}
+		]
+	}],
+	"_closedWindows": [
+		{"tabs": [{"entries": [{"url": "https://github.com/old"}], "index": 1}]}
+	],
+	"session": {"lastUpdate": 1760000000000}
+}`
+
+func writeMozLz4(t *testing.T, path, state string) {
+	t.Helper()
+	data, err := encodeMozLz4([]byte(state))
+	if err != nil {
+		t.Fatalf("encode mozlz4: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("write mozlz4: %v", err)
+	}
+}
+
+func TestFirefoxSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recovery.jsonlz4")
+	writeMozLz4(t, path, firefoxState)
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if len(entries) != 6 {
+		t.Errorf("Read() = %d entries, want 6: %+v", len(entries), entries)
+	}
+
+	matched, err := Match(path, URLMatcher(regexp.MustCompile("github")))
+	if err != nil {
+		t.Fatalf("Match() error: %v", err)
+	}
+	if len(matched) != 4 {
+		t.Errorf("Match() = %+v, want 4 github entries", matched)
+	}
+
+	n, err := Remove(path, URLMatcher(regexp.MustCompile("github")))
+	if err != nil {
+		t.Fatalf("Remove() error: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("Remove() = %d, want 3 (the frame goes with its parent)", n)
+	}
+
+	data, _ := os.ReadFile(path)
+	raw, err := DecodeMozLz4(data)
+	if err != nil {
+		t.Fatalf("decode rewritten file: %v", err)
+	}
+	var state struct {
+		Windows []struct {
+			Selected int `json:"selected"`
+			Tabs     []struct {
+				Index   int               `json:"index"`
+				Entries []json.RawMessage `json:"entries"`
+			} `json:"tabs"`
+			ClosedTabs []json.RawMessage `json:"_closedTabs"`
+		} `json:"windows"`
+		ClosedWindows []json.RawMessage `json:"_closedWindows"`
+		Session       struct {
+			LastUpdate int64 `json:"lastUpdate"`
+		} `json:"session"`
+	}
+	if err := json.Unmarshal(raw, &state); err != nil {
+		t.Fatalf("parse rewritten state: %v", err)
+	}
+	w := state.Windows[0]
+	if len(w.Tabs) != 2 || len(w.Tabs[1].Entries) != 1 || w.Tabs[1].Index != 1 || w.Selected != 2 {
+		t.Errorf("rewritten window = %+v, want 2 tabs with the second at index 1", w)
+	}
+	if len(w.ClosedTabs) != 0 || len(state.ClosedWindows) != 0 {
+		t.Errorf("closed tabs = %d, closed windows = %d, want both emptied", len(w.ClosedTabs), len(state.ClosedWindows))
+	}
+	if state.Session.LastUpdate != 1760000000000 {
+		t.Errorf("lastUpdate = %d, want unknown fields kept", state.Session.LastUpdate)
+	}
+}
+
+func TestRemoveNoMatchLeavesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recovery.jsonlz4")
+	writeMozLz4(t, path, firefoxState)
+	before, _ := os.ReadFile(path)
+
+	n, err := Remove(path, URLMatcher(regexp.MustCompile("nowhere")))
+	if err != nil || n != 0 {
+		t.Fatalf("Remove() = %d, %v, want 0, nil", n, err)
+	}
+	after, _ := os.ReadFile(path)
+	if string(before) != string(after) {
+		t.Error("Remove() rewrote a file without matches")
+	}
+}
+
+func TestReadUnknownFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recovery.baklz4.tmp")
+	if err := os.WriteFile(path, []byte("not a session"), 0600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := Read(path); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Read() error = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestDecodeMozLz4RejectsHugeSize(t *testing.T) {
+	data := []byte(mozlz4Magic + "\xff\xff\xff\xff\x10abc")
+	if _, err := DecodeMozLz4(data); err == nil {
+		t.Error("DecodeMozLz4() of a 4 GiB header on 4 bytes should fail")
+	}
+}
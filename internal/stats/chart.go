@@ -0,0 +1,110 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	sparks  = []rune("▁▂▃▄▅▆▇█")
+	eighths = []rune(" ▁▂▃▄▅▆▇█")
+	shades  = []string{"··", "░░", "▒▒", "▓▓", "██"}
+
+	// HeatColors tint the heatmap shades from quiet to busy.
+	HeatColors = []lipgloss.Color{"#4A4A4A", "#4B3F8F", "#6A4FD0", "#7D56F4", "#B49CFF"}
+)
+
+// Sparkline draws one block per value, scaled to the largest. Zero values
+// are blank so quiet days stand out.
+func Sparkline(values []int) string {
+	peak := maxOf(values)
+	var b strings.Builder
+	for _, v := range values {
+		if v == 0 || peak == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		b.WriteRune(sparks[v*(len(sparks)-1)/peak])
+	}
+	return b.String()
+}
+
+// Bar draws a bar of up to width cells for v out of peak; any v above zero
+// gets at least one cell.
+func Bar(v, peak, width int) string {
+	if v <= 0 || peak <= 0 {
+		return ""
+	}
+	return strings.Repeat("█", max(1, v*width/peak))
+}
+
+// Columns draws values as vertical bars height rows tall, one column per
+// value, scaled to the largest in eighths of a row. Like Sparkline, zero
+// values stay blank and any other value shows at least a sliver.
+func Columns(values []int, height int) string {
+	peak := maxOf(values)
+	levels := make([]int, len(values))
+	for i, v := range values {
+		if v > 0 && peak > 0 {
+			levels[i] = max(1, v*height*8/peak)
+		}
+	}
+	rows := make([]string, height)
+	for row := range rows {
+		base := (height - 1 - row) * 8
+		line := make([]rune, len(levels))
+		for i, level := range levels {
+			line[i] = eighths[min(8, max(0, level-base))]
+		}
+		rows[row] = string(line)
+	}
+	return strings.Join(rows, "\n")
+}
+
+// heatWeek lists weekdays in heatmap row order, Monday first.
+var heatWeek = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+
+// Heatmap draws hour-of-week counts as a row per weekday and two columns
+// per hour, shaded in five steps relative to the busiest hour.
+func Heatmap(h [7][24]int) string {
+	peak := 0
+	for _, day := range h {
+		peak = max(peak, maxOf(day[:]))
+	}
+
+	header := "    "
+	for hour := 0; hour < 24; hour++ {
+		if hour%6 == 0 {
+			header += fmt.Sprintf("%-12d", hour)
+		}
+	}
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(header, " "))
+	for _, wd := range heatWeek {
+		b.WriteString("\n" + wd.String()[:3] + " ")
+		for _, v := range h[wd] {
+			level := 0
+			if v > 0 {
+				level = (v*(len(shades)-1) + peak - 1) / peak
+			}
+			b.WriteString(lipgloss.NewStyle().Foreground(HeatColors[level]).Render(shades[level]))
+		}
+	}
+	b.WriteString("\n    less ")
+	for level := 1; level < len(shades); level++ {
+		b.WriteString(lipgloss.NewStyle().Foreground(HeatColors[level]).Render(string([]rune(shades[level])[0])))
+	}
+	b.WriteString(" more")
+	return b.String()
+}
+
+func maxOf(values []int) int {
+	peak := 0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	return peak
+}
@@ -0,0 +1,93 @@
+// Package stats summarizes history visits: totals per browser, the most
+// visited domains, visits per day and per hour of the week, and renders
+// them as terminal charts.
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/domain"
+)
+
+// Count is a number of visits to one domain or in one browser.
+type Count struct {
+	Name   string `json:"name"`
+	Visits int    `json:"visits"`
+}
+
+// Day is the number of visits on one local calendar day.
+type Day struct {
+	Date   string `json:"date"` // 2006-01-02
+	Visits int    `json:"visits"`
+}
+
+// Stats is a summary of a set of visits. Days and hours are in local time.
+type Stats struct {
+	Visits     int       `json:"visits"`
+	First      time.Time `json:"first_visit,omitzero"`
+	Last       time.Time `json:"last_visit,omitzero"`
+	Browsers   []Count   `json:"browsers"`
+	TopDomains []Count   `json:"top_domains"`
+	// Days runs from the first to the last day with visits, without gaps.
+	Days []Day `json:"days"`
+	// HourOfWeek counts visits by weekday (time.Sunday first) and hour.
+	HourOfWeek [7][24]int `json:"hour_of_week"`
+}
+
+// Compute summarizes entries, keeping the top most visited registrable
+// domains (see domain.Registrable).
+func Compute(entries []browser.HistoryEntry, top int) Stats {
+	s := Stats{Browsers: []Count{}, TopDomains: []Count{}, Days: []Day{}}
+	browsers := make(map[string]int)
+	domains := make(map[string]int)
+	days := make(map[string]int)
+	for _, e := range entries {
+		s.Visits++
+		if s.First.IsZero() || e.VisitTime.Before(s.First) {
+			s.First = e.VisitTime
+		}
+		if e.VisitTime.After(s.Last) {
+			s.Last = e.VisitTime
+		}
+		browsers[e.Browser]++
+		if host := browser.FieldHost.Value(e); host != "" {
+			domains[domain.Registrable(host)]++
+		}
+		local := e.VisitTime.Local()
+		days[local.Format(time.DateOnly)]++
+		s.HourOfWeek[local.Weekday()][local.Hour()]++
+	}
+
+	s.Browsers = ranked(browsers, 0)
+	s.TopDomains = ranked(domains, top)
+	if s.Visits > 0 {
+		first, last := s.First.Local(), s.Last.Local()
+		day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.Local)
+		for ; !day.After(last); day = day.AddDate(0, 0, 1) {
+			date := day.Format(time.DateOnly)
+			s.Days = append(s.Days, Day{Date: date, Visits: days[date]})
+		}
+	}
+	return s
+}
+
+// ranked sorts counts by visits, then name, keeping the first n (all if
+// n is 0).
+func ranked(counts map[string]int, n int) []Count {
+	out := make([]Count, 0, len(counts))
+	for name, v := range counts {
+		out = append(out, Count{Name: name, Visits: v})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Visits != out[j].Visits {
+			return out[i].Visits > out[j].Visits
+		}
+		return out[i].Name < out[j].Name
+	})
+	if n > 0 && len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
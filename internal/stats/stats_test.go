@@ -0,0 +1,81 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+)
+
+func TestCompute(t *testing.T) {
+	// Monday 2026-03-02, local time.
+	mon := time.Date(2026, 3, 2, 9, 30, 0, 0, time.Local)
+	entries := []browser.HistoryEntry{
+		{URL: "https://mail.example.co.uk/inbox", VisitTime: mon, Browser: "chrome"},
+		{URL: "https://www.example.co.uk/", VisitTime: mon.Add(time.Minute), Browser: "firefox"},
+		{URL: "https://go.dev/doc", VisitTime: mon.AddDate(0, 0, 2), Browser: "chrome"},
+		{URL: "file:///tmp/a.html", VisitTime: mon.Add(time.Hour), Browser: "chrome"},
+	}
+	s := Compute(entries, 1)
+
+	if s.Visits != 4 || !s.First.Equal(mon) || !s.Last.Equal(mon.AddDate(0, 0, 2)) {
+		t.Errorf("Compute() visits %d first %v last %v", s.Visits, s.First, s.Last)
+	}
+	if len(s.Browsers) != 2 || s.Browsers[0] != (Count{"chrome", 3}) {
+		t.Errorf("Compute() browsers = %v, want chrome 3 first", s.Browsers)
+	}
+	if len(s.TopDomains) != 1 || s.TopDomains[0] != (Count{"example.co.uk", 2}) {
+		t.Errorf("Compute() top domains = %v, want example.co.uk 2", s.TopDomains)
+	}
+	want := []Day{{"2026-03-02", 3}, {"2026-03-03", 0}, {"2026-03-04", 1}}
+	if len(s.Days) != len(want) {
+		t.Fatalf("Compute() days = %v, want %v", s.Days, want)
+	}
+	for i := range want {
+		if s.Days[i] != want[i] {
+			t.Errorf("Compute() days[%d] = %v, want %v", i, s.Days[i], want[i])
+		}
+	}
+	if s.HourOfWeek[time.Monday][9] != 2 || s.HourOfWeek[time.Monday][10] != 1 || s.HourOfWeek[time.Wednesday][9] != 1 {
+		t.Errorf("Compute() hour of week Monday = %v", s.HourOfWeek[time.Monday])
+	}
+}
+
+func TestComputeEmpty(t *testing.T) {
+	s := Compute(nil, 10)
+	if s.Visits != 0 || s.Days == nil || s.TopDomains == nil {
+		t.Errorf("Compute(nil) = %+v, want zero counts with empty lists", s)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := Sparkline([]int{0, 1, 4, 8}); got != " ▁▄█" {
+		t.Errorf("Sparkline() = %q", got)
+	}
+	if got := Sparkline([]int{0, 0}); got != "  " {
+		t.Errorf("Sparkline(zeros) = %q", got)
+	}
+}
+
+func TestColumns(t *testing.T) {
+	if got, want := Columns([]int{0, 1, 2, 4}, 2), "   █\n ▄██"; got != want {
+		t.Errorf("Columns() = %q, want %q", got, want)
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	var h [7][24]int
+	h[time.Monday][0] = 4
+	h[time.Sunday][23] = 1
+	lines := strings.Split(Heatmap(h), "\n")
+	if len(lines) != 9 {
+		t.Fatalf("Heatmap() has %d lines, want header, 7 days and legend", len(lines))
+	}
+	if !strings.HasPrefix(lines[1], "Mon ██··") {
+		t.Errorf("Heatmap() Monday = %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[7], "Sun ··") || !strings.HasSuffix(lines[7], "░░") {
+		t.Errorf("Heatmap() Sunday = %q", lines[7])
+	}
+}
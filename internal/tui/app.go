@@ -12,6 +12,9 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/group"
+	"github.com/odysa/histctl/internal/query"
+	"github.com/odysa/histctl/internal/stats"
 )
 
 type state int
@@ -21,6 +24,7 @@ const (
 	stateSearching
 	stateLoading
 	stateConfirmDelete
+	stateExporting
 )
 
 type historyLoadedMsg struct {
@@ -33,6 +37,12 @@ type deleteResultMsg struct {
 	err    error
 }
 
+type exportResultMsg struct {
+	path  string
+	count int
+	err   error
+}
+
 type searchResultsMsg struct {
 	entries []browser.HistoryEntry
 	err     error
@@ -40,16 +50,30 @@ type searchResultsMsg struct {
 
 type Model struct {
 	browsers      []browser.Browser
+	protect       browser.Protector
 	activeBrowser int // index into browserNames; 0 = all
 	browserNames  []string
 
 	allEntries      []browser.HistoryEntry
 	searchEntries   []browser.HistoryEntry // non-nil when DB search is active
 	filteredEntries []browser.HistoryEntry
+	highlights      []highlight  // fuzzy matches in filteredEntries, if any
 	selected        map[int]bool // index in filteredEntries
 
+	groupBy     group.Key // "" shows one row per visit
+	groupOrder  group.Order
+	groups      []group.Group   // filteredEntries grouped by groupBy
+	rows        []viewRow       // table rows when grouped
+	expanded    map[string]bool // group keys whose visits are shown
+	tableOffset int             // first table row on screen
+	deleteHosts []string        // hosts whose whole history the pending delete removes
+
+	showStats bool
+	stats     stats.Stats // of filteredEntries, kept while showStats
+
 	table       table.Model
 	searchInput textinput.Model
+	exportInput textinput.Model
 	spinner     spinner.Model
 	help        help.Model
 	keys        KeyMap
@@ -63,13 +87,18 @@ type Model struct {
 	showHelp   bool
 }
 
-func NewModel(browsers []browser.Browser) Model {
+func NewModel(browsers []browser.Browser, protect browser.Protector) Model {
 	si := textinput.New()
-	si.Placeholder = "regex pattern..."
+	si.Placeholder = "fuzzy filter, or a query such as title:go; ctrl+f searches all history"
 	si.PromptStyle = SearchPromptStyle
 	si.Prompt = "/ "
 	si.CharLimit = 200
 
+	ei := textinput.New()
+	ei.PromptStyle = SearchPromptStyle
+	ei.Prompt = "export to "
+	ei.CharLimit = 500
+
 	sp := spinner.New()
 	sp.Spinner = spinner.MiniDot
 	sp.Style = lipgloss.NewStyle().Foreground(Accent)
@@ -83,29 +112,18 @@ func NewModel(browsers []browser.Browser) Model {
 		table.WithFocused(true),
 		table.WithHeight(20),
 	)
-	s := table.DefaultStyles()
-	s.Header = s.Header.
-		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(Muted).
-		BorderBottom(true).
-		Bold(true).
-		Foreground(lipgloss.Color("#FAFAFA")).
-		Background(DimBg)
-	s.Selected = s.Selected.
-		Foreground(lipgloss.Color("#FAFAFA")).
-		Background(Accent).
-		Bold(false)
-	s.Cell = s.Cell.
-		Foreground(lipgloss.Color("#C0CAF5"))
-	t.SetStyles(s)
 
 	return Model{
 		browsers:      browsers,
+		protect:       protect,
 		activeBrowser: 0,
 		browserNames:  names,
 		selected:      make(map[int]bool),
+		groupOrder:    group.MostVisited,
+		expanded:      make(map[string]bool),
 		table:         t,
 		searchInput:   si,
+		exportInput:   ei,
 		spinner:       sp,
 		help:          help.New(),
 		keys:          DefaultKeyMap(),
@@ -120,43 +138,62 @@ func (m Model) Init() tea.Cmd {
 	)
 }
 
-func (m *Model) applyFilters() {
-	m.filteredEntries = m.filteredEntries[:0]
+// filterText is the fuzzy filter in effect: the search input while typing,
+// otherwise the last applied search.
+func (m Model) filterText() string {
+	if m.state == stateSearching {
+		return m.searchInput.Value()
+	}
+	return m.searchText
+}
 
-	source := m.allEntries
-	if m.searchEntries != nil {
-		source = m.searchEntries
+func (m *Model) applyFilters() {
+	source, text := m.allEntries, m.filterText()
+	if m.searchEntries != nil && m.state != stateSearching {
+		source, text = m.searchEntries, ""
 	}
 
 	activeName := m.browserNames[m.activeBrowser]
 
+	var entries []browser.HistoryEntry
 	for _, e := range source {
 		if activeName != "all" && e.Browser != activeName {
 			continue
 		}
-		m.filteredEntries = append(m.filteredEntries, e)
+		entries = append(entries, e)
 	}
 
+	m.highlights = nil
+	switch {
+	case query.HasTerms(text):
+		entries = queryFilter(entries, text)
+	case text != "":
+		entries, m.highlights = fuzzyFilter(entries, text)
+	}
+	m.filteredEntries = entries
+	if m.showStats {
+		m.stats = stats.Compute(entries, statsTop)
+	}
 	m.updateTableRows()
 }
 
 func (m *Model) updateTableRows() {
-	rows := make([]table.Row, len(m.filteredEntries))
-	for i, e := range m.filteredEntries {
-		urlW := m.urlWidth()
-		url := truncate(e.URL, urlW)
-		if m.selected[i] {
-			url = "> " + truncate(e.URL, urlW-2)
-		}
-		title := truncate(e.Title, 30)
-		rows[i] = table.Row{
-			url,
-			title,
-			relativeTime(e.VisitTime),
-			e.Browser,
+	m.buildRows()
+	rows := make([]table.Row, m.rowCount())
+	for i := range rows {
+		cells := m.rowCells(i)
+		row := make(table.Row, len(cells))
+		for j, c := range cells {
+			row[j] = c.text
 		}
+		rows[i] = row
 	}
 	m.table.SetRows(rows)
+	// SetRows leaves the cursor at -1 after an empty table.
+	if m.table.Cursor() < 0 && len(rows) > 0 {
+		m.table.SetCursor(0)
+	}
+	m.followCursor()
 }
 
 func (m *Model) resizeTable() {
@@ -169,23 +206,6 @@ func (m *Model) resizeTable() {
 	m.updateTableRows()
 }
 
-func (m *Model) urlWidth() int {
-	w := m.width - 30 - 12 - 8 - 10
-	if w < 20 {
-		w = 20
-	}
-	return w
-}
-
-func (m *Model) columns() []table.Column {
-	return []table.Column{
-		{Title: "URL", Width: m.urlWidth()},
-		{Title: "Title", Width: 30},
-		{Title: "Time", Width: 12},
-		{Title: "Browser", Width: 8},
-	}
-}
-
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -196,7 +216,9 @@ func truncate(s string, maxLen int) string {
 	return s[:maxLen-1] + "…"
 }
 
-func relativeTime(t time.Time) string {
+// RelativeTime formats t the way the history table shows it, such as
+// "5m ago", "yesterday" or "Jan 02".
+func RelativeTime(t time.Time) string {
 	now := time.Now()
 	d := now.Sub(t)
 	switch {
@@ -234,8 +256,8 @@ func (m Model) browserCount(name string) int {
 	return count
 }
 
-func Run(browsers []browser.Browser) error {
-	m := NewModel(browsers)
+func Run(browsers []browser.Browser, protect browser.Protector) error {
+	m := NewModel(browsers, protect)
 	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	_, err := p.Run()
 	return err
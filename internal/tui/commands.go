@@ -3,15 +3,19 @@ package tui
 import (
 	"context"
 	"fmt"
-	"regexp"
+	"os"
 	"sort"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/odysa/histctl/internal/backup"
 	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/export"
+	"github.com/odysa/histctl/internal/group"
 	"github.com/odysa/histctl/internal/process"
+	"github.com/odysa/histctl/internal/query"
 )
 
 func (m Model) loadHistory() tea.Cmd {
@@ -30,16 +34,22 @@ func (m Model) loadHistory() tea.Cmd {
 	}
 }
 
+// searchOptions compiles the search text as a query such as
+// "host:github.com NOT path:/settings"; see the query package.
+func searchOptions(text string) (browser.ListOptions, error) {
+	return query.Compile(text, time.Now())
+}
+
 func (m Model) searchHistory(pattern string) tea.Cmd {
 	return func() tea.Msg {
-		re, err := regexp.Compile("(?i)" + pattern)
+		opts, err := searchOptions(pattern)
 		if err != nil {
-			return searchResultsMsg{err: fmt.Errorf("invalid regex: %s", pattern)}
+			return searchResultsMsg{err: err}
 		}
 		ctx := context.Background()
 		var all []browser.HistoryEntry
 		for _, b := range m.browsers {
-			entries, err := b.List(ctx, browser.ListOptions{Pattern: re})
+			entries, err := b.List(ctx, opts)
 			if err != nil {
 				continue
 			}
@@ -69,6 +79,19 @@ func (m Model) performDelete() tea.Cmd {
 				continue
 			}
 
+			// Delete exactly the selected visits, and leave the database
+			// alone if protection or bookmarks keep all of them.
+			opts := browser.DeleteOptions{Entries: entries, DryRun: true, Protect: m.protect}
+			preview, err := b.Delete(ctx, opts)
+			if err != nil {
+				return deleteResultMsg{err: err}
+			}
+			if len(preview.Entries) == 0 {
+				totalResult.Matched += preview.Matched
+				totalResult.Protected = append(totalResult.Protected, preview.Protected...)
+				continue
+			}
+
 			running, err := process.IsRunning(b.ProcessName())
 			if err != nil || running {
 				return deleteResultMsg{err: fmt.Errorf("%s is running — close it first", b.Name())}
@@ -82,24 +105,92 @@ func (m Model) performDelete() tea.Cmd {
 				return deleteResultMsg{err: fmt.Errorf("backup failed: %w", err)}
 			}
 
-			urlPatterns := make([]string, len(entries))
-			for i, e := range entries {
-				urlPatterns[i] = regexp.QuoteMeta(e.URL)
+			opts.DryRun = false
+			result, err := b.Delete(ctx, opts)
+			if err != nil {
+				return deleteResultMsg{err: err}
+			}
+			totalResult.Matched += result.Matched
+			totalResult.Deleted += result.Deleted
+			totalResult.Protected = append(totalResult.Protected, result.Protected...)
+		}
+
+		return deleteResultMsg{result: totalResult}
+	}
+}
+
+// deleteHostHistory deletes every visit to hosts from the browsers of the
+// active pill, backing up each database that has any.
+func (m Model) deleteHostHistory(hosts []string) tea.Cmd {
+	set := make(map[string]bool, len(hosts))
+	for _, h := range hosts {
+		set[h] = true
+	}
+	opts := browser.ListOptions{Filter: func(e browser.HistoryEntry) bool {
+		return set[group.ByHost.Of(e)]
+	}}
+	active := m.browserNames[m.activeBrowser]
+
+	return func() tea.Msg {
+		ctx := context.Background()
+		var totalResult browser.DeleteResult
+		for _, b := range m.browsers {
+			if active != "all" && b.Name() != active {
+				continue
+			}
+			preview, err := b.Delete(ctx, browser.DeleteOptions{ListOptions: opts, DryRun: true, Protect: m.protect})
+			if err != nil {
+				return deleteResultMsg{err: err}
+			}
+			if preview.Matched == 0 {
+				continue
+			}
+
+			running, err := process.IsRunning(b.ProcessName())
+			if err != nil || running {
+				return deleteResultMsg{err: fmt.Errorf("%s is running — close it first", b.Name())}
+			}
+			dbPath, err := b.DBPath()
+			if err != nil {
+				return deleteResultMsg{err: err}
+			}
+			if _, err := backup.Create(dbPath); err != nil {
+				return deleteResultMsg{err: fmt.Errorf("backup failed: %w", err)}
 			}
-			pattern := regexp.MustCompile("^(" + strings.Join(urlPatterns, "|") + ")$")
 
-			result, err := b.Delete(ctx, pattern, false)
+			result, err := b.Delete(ctx, browser.DeleteOptions{ListOptions: opts, Protect: m.protect})
 			if err != nil {
 				return deleteResultMsg{err: err}
 			}
 			totalResult.Matched += result.Matched
 			totalResult.Deleted += result.Deleted
+			totalResult.Protected = append(totalResult.Protected, result.Protected...)
 		}
-
 		return deleteResultMsg{result: totalResult}
 	}
 }
 
+// exportEntries writes entries to path in the format of its extension.
+func exportEntries(path string, entries []browser.HistoryEntry) tea.Cmd {
+	return func() tea.Msg {
+		format := export.ForPath(path)
+		if format == "" {
+			return exportResultMsg{err: fmt.Errorf("unknown extension; use one of %s",
+				strings.Join(export.Formats(), ", "))}
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			return exportResultMsg{err: err}
+		}
+		w, _ := export.New(format, f)
+		err = export.WriteAll(w, entries)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return exportResultMsg{path: path, count: len(entries), err: err}
+	}
+}
+
 func sortEntries(entries []browser.HistoryEntry) {
 	sort.Slice(entries, func(i, j int) bool {
 		return entries[i].VisitTime.After(entries[j].VisitTime)
@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/fuzzy"
+	"github.com/odysa/histctl/internal/query"
+)
+
+// highlight holds the byte offsets of fuzzy-matched runes in an entry's URL
+// and title.
+type highlight struct {
+	url, title []int
+}
+
+// fuzzyFilter keeps the entries matching every space-separated term of
+// text in their URL or title, best matches first, with the matched
+// positions for highlighting.
+func fuzzyFilter(entries []browser.HistoryEntry, text string) ([]browser.HistoryEntry, []highlight) {
+	terms := strings.Fields(text)
+	type hit struct {
+		entry browser.HistoryEntry
+		score int
+		hl    highlight
+	}
+	var hits []hit
+	for _, e := range entries {
+		if score, hl, ok := matchEntry(terms, e); ok {
+			hits = append(hits, hit{e, score, hl})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+
+	out := make([]browser.HistoryEntry, len(hits))
+	hls := make([]highlight, len(hits))
+	for i, h := range hits {
+		out[i], hls[i] = h.entry, h.hl
+	}
+	return out, hls
+}
+
+// queryFilter keeps the entries matching text as a query such as
+// "title:go after:7d". While the query is incomplete, as it is mid-typing,
+// the entries are kept as they are.
+func queryFilter(entries []browser.HistoryEntry, text string) []browser.HistoryEntry {
+	x, err := query.Parse(text, time.Now())
+	if err != nil {
+		return entries
+	}
+	var out []browser.HistoryEntry
+	for _, e := range entries {
+		if x.Match(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func matchEntry(terms []string, e browser.HistoryEntry) (int, highlight, bool) {
+	var total int
+	var hl highlight
+	for _, t := range terms {
+		u, urlOK := fuzzy.Find(t, e.URL)
+		ti, titleOK := fuzzy.Find(t, e.Title)
+		switch {
+		case urlOK && (!titleOK || u.Score >= ti.Score):
+			total += u.Score
+			hl.url = append(hl.url, u.Positions...)
+		case titleOK:
+			total += ti.Score
+			hl.title = append(hl.title, ti.Positions...)
+		default:
+			return 0, highlight{}, false
+		}
+	}
+	slices.Sort(hl.url)
+	slices.Sort(hl.title)
+	hl.url = slices.Compact(hl.url)
+	hl.title = slices.Compact(hl.title)
+	return total, hl, true
+}
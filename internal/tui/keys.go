@@ -9,7 +9,13 @@ type KeyMap struct {
 	All    key.Binding
 	Delete key.Binding
 	Search key.Binding
+	Full   key.Binding
 	Tab    key.Binding
+	Group  key.Binding
+	Sort   key.Binding
+	Expand key.Binding
+	Stats  key.Binding
+	Export key.Binding
 	Apply  key.Binding
 	Cancel key.Binding
 	Help   key.Binding
@@ -23,8 +29,14 @@ func DefaultKeyMap() KeyMap {
 		Select: key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "select")),
 		All:    key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "select all")),
 		Delete: key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
-		Search: key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "search")),
+		Search: key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+		Full:   key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "search all history")),
 		Tab:    key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "browser")),
+		Group:  key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "group by url/host")),
+		Sort:   key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sort by visits/recent")),
+		Expand: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "expand group")),
+		Stats:  key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "stats")),
+		Export: key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "export")),
 		Apply:  key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "apply")),
 		Cancel: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
 		Help:   key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
@@ -33,13 +45,14 @@ func DefaultKeyMap() KeyMap {
 }
 
 func (k KeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Search, k.Select, k.All, k.Delete, k.Tab, k.Help, k.Quit}
+	return []key.Binding{k.Search, k.Select, k.All, k.Delete, k.Group, k.Stats, k.Tab, k.Help, k.Quit}
 }
 
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Select, k.All},
-		{k.Search, k.Delete, k.Tab},
+		{k.Search, k.Full, k.Delete, k.Export, k.Tab},
+		{k.Group, k.Sort, k.Expand, k.Stats},
 		{k.Help, k.Quit},
 	}
 }
@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/odysa/histctl/internal/stats"
+)
+
+// statsTop is how many domains the stats screen lists, as many as fit
+// beside the heatmap.
+const statsTop = 8
+
+// updateStats handles keys on the stats screen. Table keys do nothing
+// there; searching and switching browsers update the charts.
+func (m Model) updateStats(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Stats), key.Matches(msg, m.keys.Cancel):
+		m.showStats = false
+		return m, nil
+
+	case key.Matches(msg, m.keys.Search):
+		return m.enterSearchMode()
+
+	case key.Matches(msg, m.keys.Full):
+		return m.fullSearch()
+
+	case key.Matches(msg, m.keys.Tab):
+		m.activeBrowser = (m.activeBrowser + 1) % len(m.browserNames)
+		m.selected = make(map[int]bool)
+		m.applyFilters()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Help):
+		m.showHelp = !m.showHelp
+		return m, nil
+	}
+	return m, nil
+}
+
+// renderStats draws the stats screen for filteredEntries: visits per day
+// over the width of the screen, then the hour-of-week heatmap beside the
+// top domains, or below it on narrow screens.
+func (m Model) renderStats() string {
+	s := m.stats
+	heading := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FAFAFA"))
+	subtle := lipgloss.NewStyle().Foreground(Subtle)
+	accent := lipgloss.NewStyle().Foreground(Accent)
+
+	heatmap := heading.Render("Hour of week") + "\n" + stats.Heatmap(s.HourOfWeek)
+
+	top := []string{heading.Render("Top domains")}
+	if len(s.TopDomains) > 0 {
+		nameW := 0
+		for _, c := range s.TopDomains {
+			nameW = max(nameW, len(c.Name))
+		}
+		nameW = min(nameW, 30)
+		peak := s.TopDomains[0].Visits
+		for _, c := range s.TopDomains {
+			top = append(top, fmt.Sprintf("%-*s %s %s", nameW, truncate(c.Name, nameW),
+				subtle.Render(fmt.Sprintf("%5d", c.Visits)), accent.Render(stats.Bar(c.Visits, peak, 20))))
+		}
+	} else {
+		top = append(top, subtle.Render("no domains"))
+	}
+	domains := strings.Join(top, "\n")
+
+	bottom := heatmap + "\n\n" + domains
+	if lipgloss.Width(heatmap)+4+lipgloss.Width(domains) <= m.width-2 {
+		bottom = lipgloss.JoinHorizontal(lipgloss.Top, heatmap, "    ", domains)
+	}
+
+	// The per-day chart gets the rows left after the header, search bar,
+	// status bar and help, its own heading and dates, and the bottom row.
+	chartH := min(10, max(3, m.height-12-3-lipgloss.Height(bottom)))
+	return lipgloss.NewStyle().MarginLeft(2).Render(m.renderDays(chartH) + "\n\n" + bottom)
+}
+
+// renderDays draws visits per day as a bar chart height rows tall, showing
+// the most recent days that fit and widening bars when few days do.
+func (m Model) renderDays(height int) string {
+	days := m.stats.Days
+	peak := 0
+	for _, d := range days {
+		peak = max(peak, d.Visits)
+	}
+	axis := len(fmt.Sprint(peak)) + 1
+	width := max(1, m.width-4-axis)
+	if len(days) > width {
+		days = days[len(days)-width:]
+	}
+	cols := min(3, width/len(days))
+	var values []int
+	for _, d := range days {
+		for range cols {
+			values = append(values, d.Visits)
+		}
+	}
+
+	subtle := lipgloss.NewStyle().Foreground(Subtle)
+	bars := strings.Split(stats.Columns(values, height), "\n")
+	for i := range bars {
+		label := ""
+		switch i {
+		case 0:
+			label = fmt.Sprint(peak)
+		case len(bars) - 1:
+			label = "0"
+		}
+		bars[i] = subtle.Render(fmt.Sprintf("%*s ", axis-1, label)) +
+			lipgloss.NewStyle().Foreground(Accent).Render(bars[i])
+	}
+
+	first, last := days[0].Date, days[len(days)-1].Date
+	dates := first
+	if pad := len(values) - len(first) - len(last); pad > 0 {
+		dates += strings.Repeat(" ", pad) + last
+	} else if first != last {
+		dates += " – " + last
+	}
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FAFAFA")).
+		Render(fmt.Sprintf("Visits per day · %d visits", m.stats.Visits))
+	return title + "\n" + strings.Join(bars, "\n") + "\n" + subtle.Render(strings.Repeat(" ", axis)+dates)
+}
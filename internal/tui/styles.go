@@ -54,6 +54,30 @@ var (
 	SearchPromptStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("#FF79C6"))
 
+	TableHeaderStyle = lipgloss.NewStyle().
+				Bold(true).
+				Padding(0, 1).
+				BorderStyle(lipgloss.NormalBorder()).
+				BorderForeground(Muted).
+				BorderBottom(true).
+				Foreground(lipgloss.Color("#FAFAFA")).
+				Background(DimBg)
+
+	TableCellStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#C0CAF5"))
+
+	TableCursorStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FAFAFA")).
+				Background(Accent)
+
+	MatchStyle = TableCellStyle.
+			Foreground(lipgloss.Color("#FF79C6")).
+			Bold(true)
+
+	MatchCursorStyle = TableCursorStyle.
+				Bold(true).
+				Underline(true)
+
 	StatusBarStyle = lipgloss.NewStyle().
 			Foreground(Subtle)
 
@@ -0,0 +1,315 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/odysa/histctl/internal/group"
+)
+
+// The bubbles table still tracks the cursor and handles navigation keys,
+// but rows are rendered here: it truncates cells by rune width, which
+// counts the escape codes of highlighted matches and cuts them apart.
+
+// cell is the text shown in a table cell and the byte offsets in it to
+// highlight.
+type cell struct {
+	text string
+	hl   []int
+}
+
+// viewRow is a table row in grouped mode: a group, or one of its visits
+// when the group is expanded.
+type viewRow struct {
+	group int // index in groups
+	entry int // index in filteredEntries; -1 for the group row
+}
+
+func (m Model) rowCount() int {
+	if m.groupBy != "" {
+		return len(m.rows)
+	}
+	return len(m.filteredEntries)
+}
+
+// rowEntries returns the filteredEntries indices behind table row r: the
+// visit, or every visit of a group.
+func (m Model) rowEntries(r int) []int {
+	if m.groupBy == "" {
+		return []int{r}
+	}
+	if vr := m.rows[r]; vr.entry >= 0 {
+		return []int{vr.entry}
+	}
+	return m.groups[m.rows[r].group].Entries
+}
+
+// selectedHosts returns the hosts to delete outright in host mode: the
+// groups whose visits are all selected, or the group under the cursor when
+// nothing is. It returns nil when the selection includes single visits,
+// which are deleted as usual.
+func (m Model) selectedHosts() []string {
+	if m.groupBy != group.ByHost || m.rowCount() == 0 {
+		return nil
+	}
+	if len(m.selected) == 0 {
+		if vr := m.rows[m.table.Cursor()]; vr.entry < 0 {
+			return []string{m.groups[vr.group].Key}
+		}
+		return nil
+	}
+	var hosts []string
+	for _, g := range m.groups {
+		n := 0
+		for _, i := range g.Entries {
+			if m.selected[i] {
+				n++
+			}
+		}
+		switch n {
+		case 0:
+		case len(g.Entries):
+			hosts = append(hosts, g.Key)
+		default:
+			return nil
+		}
+	}
+	return hosts
+}
+
+func (m Model) rowSelected(r int) bool {
+	for _, i := range m.rowEntries(r) {
+		if !m.selected[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// buildRows groups filteredEntries when a group mode is on.
+func (m *Model) buildRows() {
+	m.groups, m.rows = nil, nil
+	if m.groupBy == "" {
+		return
+	}
+	m.groups = group.By(m.filteredEntries, m.groupBy)
+	group.Sort(m.groups, m.groupOrder)
+	for gi, g := range m.groups {
+		m.rows = append(m.rows, viewRow{group: gi, entry: -1})
+		if m.expanded[g.Key] {
+			for _, i := range g.Entries {
+				m.rows = append(m.rows, viewRow{group: gi, entry: i})
+			}
+		}
+	}
+}
+
+func (m Model) rowCells(r int) []cell {
+	if m.groupBy == "" {
+		return m.visitCells(r, "")
+	}
+	vr := m.rows[r]
+	if vr.entry >= 0 {
+		cells := m.visitCells(vr.entry, "    ")
+		return []cell{cells[0], cells[1], {}, {}, cells[2], cells[3]}
+	}
+	return m.groupCells(r, m.groups[vr.group])
+}
+
+func (m Model) visitCells(i int, indent string) []cell {
+	e := m.filteredEntries[i]
+	var hl highlight
+	if i < len(m.highlights) {
+		hl = m.highlights[i]
+	}
+	prefix := indent
+	if m.selected[i] {
+		prefix += "> "
+	}
+	return []cell{
+		fitCell(prefix, e.URL, hl.url, m.urlWidth()),
+		fitCell("", e.Title, hl.title, 30),
+		{text: RelativeTime(e.VisitTime)},
+		{text: e.Browser},
+	}
+}
+
+func (m Model) groupCells(r int, g group.Group) []cell {
+	prefix := "▸ "
+	if m.expanded[g.Key] {
+		prefix = "▾ "
+	}
+	if m.rowSelected(r) {
+		prefix += "> "
+	}
+	browsers := g.Browsers[0]
+	if n := len(g.Browsers); n > 1 {
+		browsers = fmt.Sprintf("%s+%d", browsers, n-1)
+	}
+	// URL groups highlight the fuzzy match of the visit whose title they show.
+	var hl highlight
+	if m.groupBy == group.ByURL && m.highlights != nil {
+		for _, i := range g.Entries {
+			if m.filteredEntries[i].VisitTime.Equal(g.Last) {
+				hl = m.highlights[i]
+				break
+			}
+		}
+	}
+	return []cell{
+		fitCell(prefix, g.Key, hl.url, m.urlWidth()),
+		fitCell("", g.Title, hl.title, 30),
+		{text: fmt.Sprint(g.Visits)},
+		{text: RelativeTime(g.First)},
+		{text: RelativeTime(g.Last)},
+		{text: browsers},
+	}
+}
+
+// fitCell truncates s to fit width after prefix, keeping the highlight
+// offsets that survive.
+func fitCell(prefix, s string, hl []int, width int) cell {
+	t := truncate(s, width-lipgloss.Width(prefix))
+	kept := len(t)
+	if t != s && strings.HasSuffix(t, "…") {
+		kept -= len("…")
+	}
+	var offsets []int
+	for _, o := range hl {
+		if o < kept {
+			offsets = append(offsets, o+len(prefix))
+		}
+	}
+	return cell{prefix + t, offsets}
+}
+
+func (m Model) columns() []table.Column {
+	if m.groupBy != "" {
+		key := "URL"
+		if m.groupBy == group.ByHost {
+			key = "Host"
+		}
+		visits, last := "Visits ↓", "Last"
+		if m.groupOrder == group.MostRecent {
+			visits, last = "Visits", "Last ↓"
+		}
+		return []table.Column{
+			{Title: key, Width: m.urlWidth()},
+			{Title: "Title", Width: 30},
+			{Title: visits, Width: 8},
+			{Title: "First", Width: 12},
+			{Title: last, Width: 12},
+			{Title: "Browser", Width: 8},
+		}
+	}
+	return []table.Column{
+		{Title: "URL", Width: m.urlWidth()},
+		{Title: "Title", Width: 30},
+		{Title: "Time", Width: 12},
+		{Title: "Browser", Width: 8},
+	}
+}
+
+func (m Model) urlWidth() int {
+	w := m.width - 30 - 12 - 8 - 10
+	if m.groupBy != "" {
+		w -= 8 + 12 + 2*2
+	}
+	if w < 20 {
+		w = 20
+	}
+	return w
+}
+
+func (m Model) renderTableHeader() string {
+	cols := m.columns()
+	cells := make([]string, len(cols))
+	for i, c := range cols {
+		title := lipgloss.NewStyle().Width(c.Width).MaxWidth(c.Width).Inline(true).Render(c.Title)
+		cells[i] = TableHeaderStyle.Render(title)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, cells...)
+}
+
+func (m Model) tableRowsHeight() int {
+	return max(1, m.table.Height()-lipgloss.Height(m.renderTableHeader()))
+}
+
+// followCursor scrolls the table so the cursor row is visible.
+func (m *Model) followCursor() {
+	h := m.tableRowsHeight()
+	cursor := m.table.Cursor()
+	if cursor < m.tableOffset {
+		m.tableOffset = cursor
+	}
+	if cursor >= m.tableOffset+h {
+		m.tableOffset = cursor - h + 1
+	}
+	m.tableOffset = max(0, min(m.tableOffset, m.rowCount()-h))
+}
+
+func (m Model) renderTable() string {
+	cols := m.columns()
+	h := m.tableRowsHeight()
+	lines := make([]string, 0, h)
+	for r := m.tableOffset; r < m.rowCount() && len(lines) < h; r++ {
+		base, match := TableCellStyle, MatchStyle
+		if r == m.table.Cursor() {
+			base, match = TableCursorStyle, MatchCursorStyle
+		}
+		var row strings.Builder
+		for j, c := range m.rowCells(r) {
+			row.WriteString(renderCell(c, cols[j].Width, base, match))
+		}
+		lines = append(lines, row.String())
+	}
+	for len(lines) < h {
+		lines = append(lines, "")
+	}
+	return m.renderTableHeader() + "\n" + strings.Join(lines, "\n")
+}
+
+// renderCell pads c to width w plus one space either side, drawing the
+// highlighted runes with match and the rest with base.
+func renderCell(c cell, w int, base, match lipgloss.Style) string {
+	var b strings.Builder
+	b.WriteString(base.Render(" "))
+	text := c.text
+	if lipgloss.Width(text) > w {
+		text = lipgloss.NewStyle().MaxWidth(w).Render(text)
+	}
+
+	hl, run, inMatch := c.hl, 0, false
+	flush := func(end int) {
+		if end > run {
+			style := base
+			if inMatch {
+				style = match
+			}
+			b.WriteString(style.Render(text[run:end]))
+		}
+		run = end
+	}
+	for i := 0; i < len(text); {
+		_, size := utf8.DecodeRuneInString(text[i:])
+		for len(hl) > 0 && hl[0] < i {
+			hl = hl[1:]
+		}
+		if isMatch := len(hl) > 0 && hl[0] == i; isMatch != inMatch {
+			flush(i)
+			inMatch = isMatch
+		}
+		i += size
+	}
+	flush(len(text))
+
+	if pad := w - lipgloss.Width(text); pad > 0 {
+		b.WriteString(base.Render(strings.Repeat(" ", pad)))
+	}
+	b.WriteString(base.Render(" "))
+	return b.String()
+}
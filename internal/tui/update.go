@@ -2,14 +2,29 @@ package tui
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/odysa/histctl/internal/browser"
+	"github.com/odysa/histctl/internal/group"
+	"github.com/odysa/histctl/internal/query"
 )
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if nm, ok := next.(Model); ok {
+		nm.followCursor()
+		return nm, cmd
+	}
+	return next, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -24,9 +39,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.state = stateViewing
 			return m, nil
 		}
+		full := m.searchEntries != nil
 		m.allEntries = msg.entries
 		m.searchEntries = nil
-		if m.searchText != "" {
+		if full {
 			return m, m.searchHistory(m.searchText)
 		}
 		m.state = stateViewing
@@ -45,15 +61,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case deleteResultMsg:
+		m.selected = make(map[int]bool)
+		m.deleteHosts = nil
 		if msg.err != nil {
 			m.statusMsg = ErrorStyle.Render(fmt.Sprintf("Delete failed: %v", msg.err))
 		} else {
-			m.statusMsg = lipgloss.NewStyle().Foreground(Success).Render(
-				fmt.Sprintf("Deleted %d entries", msg.result.Deleted))
+			text := fmt.Sprintf("Deleted %d entries", msg.result.Deleted)
+			if n := len(msg.result.Protected); n > 0 {
+				text += fmt.Sprintf(", kept %d protected", n)
+			}
+			m.statusMsg = lipgloss.NewStyle().Foreground(Success).Render(text)
 		}
 		m.state = stateLoading
 		return m, m.loadHistory()
 
+	case exportResultMsg:
+		if msg.err != nil {
+			m.statusMsg = ErrorStyle.Render(fmt.Sprintf("Export failed: %v", msg.err))
+		} else {
+			m.statusMsg = lipgloss.NewStyle().Foreground(Success).Render(
+				fmt.Sprintf("Exported %d entries to %s", msg.count, msg.path))
+		}
+		return m, nil
+
 	case spinner.TickMsg:
 		if m.state == stateLoading {
 			var cmd tea.Cmd
@@ -74,7 +104,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m.enterSearchMode()
 			}
 		}
-		if m.state == stateViewing {
+		if m.state == stateViewing && !m.showStats {
 			var cmd tea.Cmd
 			m.table, cmd = m.table.Update(msg)
 			return m, cmd
@@ -87,6 +117,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateSearching(msg)
 		case stateConfirmDelete:
 			return m.updateConfirmDelete(msg)
+		case stateExporting:
+			return m.updateExporting(msg)
 		case stateViewing:
 			return m.updateViewing(msg)
 		}
@@ -96,6 +128,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) updateViewing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.showStats {
+		return m.updateStats(msg)
+	}
 	switch {
 	case key.Matches(msg, m.keys.Quit):
 		return m, tea.Quit
@@ -103,16 +138,22 @@ func (m Model) updateViewing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.Search):
 		return m.enterSearchMode()
 
+	case key.Matches(msg, m.keys.Full):
+		return m.fullSearch()
+
 	case key.Matches(msg, m.keys.Select):
 		cursor := m.table.Cursor()
-		if cursor < len(m.filteredEntries) {
-			if m.selected[cursor] {
-				delete(m.selected, cursor)
-			} else {
-				m.selected[cursor] = true
+		if cursor < m.rowCount() {
+			on := !m.rowSelected(cursor)
+			for _, i := range m.rowEntries(cursor) {
+				if on {
+					m.selected[i] = true
+				} else {
+					delete(m.selected, i)
+				}
 			}
 			m.updateTableRows()
-			if cursor < len(m.filteredEntries)-1 {
+			if cursor < m.rowCount()-1 {
 				m.table.SetCursor(cursor + 1)
 			}
 		}
@@ -124,11 +165,80 @@ func (m Model) updateViewing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case key.Matches(msg, m.keys.Delete):
+		// In host mode, d on whole hosts deletes all their history, not
+		// only the loaded visits.
+		if m.deleteHosts = m.selectedHosts(); m.deleteHosts != nil {
+			m.state = stateConfirmDelete
+			return m, nil
+		}
+		// In grouped mode, d on an unselected group deletes that group.
+		if len(m.selected) == 0 && m.groupBy != "" && m.table.Cursor() < m.rowCount() {
+			for _, i := range m.rowEntries(m.table.Cursor()) {
+				m.selected[i] = true
+			}
+			m.updateTableRows()
+		}
 		if len(m.selected) > 0 {
 			m.state = stateConfirmDelete
 		}
 		return m, nil
 
+	case key.Matches(msg, m.keys.Group):
+		switch m.groupBy {
+		case "":
+			m.groupBy = group.ByURL
+		case group.ByURL:
+			m.groupBy = group.ByHost
+		default:
+			m.groupBy = ""
+		}
+		m.expanded = make(map[string]bool)
+		m.table.SetColumns(m.columns())
+		m.updateTableRows()
+		m.table.SetCursor(0)
+		return m, nil
+
+	case key.Matches(msg, m.keys.Sort):
+		if m.groupBy == "" {
+			return m, nil
+		}
+		if m.groupOrder == group.MostVisited {
+			m.groupOrder = group.MostRecent
+		} else {
+			m.groupOrder = group.MostVisited
+		}
+		m.table.SetColumns(m.columns())
+		m.updateTableRows()
+		m.table.SetCursor(0)
+		return m, nil
+
+	case key.Matches(msg, m.keys.Expand):
+		if m.groupBy == "" || m.table.Cursor() >= m.rowCount() {
+			return m, nil
+		}
+		vr := m.rows[m.table.Cursor()]
+		k := m.groups[vr.group].Key
+		m.expanded[k] = !m.expanded[k]
+		m.updateTableRows()
+		if vr.entry >= 0 {
+			// Collapsing from a visit moves the cursor to its group.
+			for r, row := range m.rows {
+				if row.group == vr.group {
+					m.table.SetCursor(r)
+					break
+				}
+			}
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Export):
+		return m.enterExportMode()
+
+	case key.Matches(msg, m.keys.Stats):
+		m.showStats = true
+		m.applyFilters()
+		return m, nil
+
 	case key.Matches(msg, m.keys.Tab):
 		m.activeBrowser = (m.activeBrowser + 1) % len(m.browserNames)
 		m.selected = make(map[int]bool)
@@ -151,40 +261,121 @@ func (m Model) updateSearching(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.Cancel):
 		m.state = stateViewing
 		m.searchInput.Blur()
+		m.selected = make(map[int]bool)
+		m.applyFilters()
 		return m, nil
 
 	case key.Matches(msg, m.keys.Apply):
 		m.searchText = m.searchInput.Value()
 		m.searchInput.Blur()
-		m.selected = make(map[int]bool)
-		if m.searchText == "" {
-			m.searchEntries = nil
-			m.state = stateViewing
-			m.applyFilters()
-			return m, nil
+		m.state = stateViewing
+		if query.HasTerms(m.searchText) {
+			// Field prefixes and operators are a query, not fuzzy text.
+			return m.fullSearch()
 		}
-		m.state = stateLoading
-		return m, m.searchHistory(m.searchText)
+		m.searchEntries = nil
+		m.applyFilters()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Full):
+		m.searchText = m.searchInput.Value()
+		m.searchInput.Blur()
+		m.state = stateViewing
+		return m.fullSearch()
 
 	default:
+		prev := m.searchInput.Value()
 		var cmd tea.Cmd
 		m.searchInput, cmd = m.searchInput.Update(msg)
+		if m.searchInput.Value() != prev {
+			m.selected = make(map[int]bool)
+			m.applyFilters()
+			m.table.SetCursor(0)
+		}
 		return m, cmd
 	}
 }
 
+// fullSearch runs the search text as a regex or query against every
+// browser database instead of fuzzy-filtering the loaded entries.
+func (m Model) fullSearch() (tea.Model, tea.Cmd) {
+	m.selected = make(map[int]bool)
+	m.err = nil
+	if m.searchText == "" {
+		m.searchEntries = nil
+		m.applyFilters()
+		return m, nil
+	}
+	m.state = stateLoading
+	return m, m.searchHistory(m.searchText)
+}
+
 func (m Model) updateConfirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "y", "Y":
 		m.state = stateLoading
+		if m.deleteHosts != nil {
+			return m, m.deleteHostHistory(m.deleteHosts)
+		}
 		return m, m.performDelete()
 	default:
+		m.deleteHosts = nil
 		m.state = stateViewing
 		m.statusMsg = lipgloss.NewStyle().Foreground(Subtle).Render("Delete cancelled")
 		return m, nil
 	}
 }
 
+// enterExportMode asks where to export the selection, or every entry shown
+// when nothing is selected. The extension of the file picks the format.
+func (m Model) enterExportMode() (tea.Model, tea.Cmd) {
+	if len(m.exportSelection()) == 0 {
+		return m, nil
+	}
+	m.state = stateExporting
+	m.exportInput.SetValue("histctl-" + time.Now().Format("20060102-150405") + ".csv")
+	m.exportInput.Focus()
+	return m, m.exportInput.Cursor.BlinkCmd()
+}
+
+func (m Model) updateExporting(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Cancel):
+		m.state = stateViewing
+		m.exportInput.Blur()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Apply):
+		m.state = stateViewing
+		m.exportInput.Blur()
+		path := strings.TrimSpace(m.exportInput.Value())
+		if path == "" {
+			return m, nil
+		}
+		return m, exportEntries(path, m.exportSelection())
+
+	default:
+		var cmd tea.Cmd
+		m.exportInput, cmd = m.exportInput.Update(msg)
+		return m, cmd
+	}
+}
+
+// exportSelection returns the selected entries in table order, or all
+// filtered entries if none are selected.
+func (m Model) exportSelection() []browser.HistoryEntry {
+	if len(m.selected) == 0 {
+		return m.filteredEntries
+	}
+	var out []browser.HistoryEntry
+	for i, e := range m.filteredEntries {
+		if m.selected[i] {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
 func (m Model) enterSearchMode() (tea.Model, tea.Cmd) {
 	m.state = stateSearching
 	m.searchInput.SetValue(m.searchText)
@@ -22,8 +22,8 @@ func (m Model) View() string {
 		sections = append(sections, fmt.Sprintf("\n  %s %s\n", m.spinner.View(), loadingText))
 	} else if len(m.filteredEntries) == 0 {
 		emptyMsg := "No history entries"
-		if m.searchText != "" {
-			emptyMsg = fmt.Sprintf("No results for \"%s\"", m.searchText)
+		if text := m.filterText(); text != "" {
+			emptyMsg = fmt.Sprintf("No results for \"%s\"", text)
 		}
 		sections = append(sections, lipgloss.NewStyle().
 			Foreground(Subtle).
@@ -32,8 +32,10 @@ func (m Model) View() string {
 			Align(lipgloss.Center).
 			Padding(3, 0).
 			Render(emptyMsg))
+	} else if m.showStats {
+		sections = append(sections, m.renderStats())
 	} else {
-		sections = append(sections, m.table.View())
+		sections = append(sections, m.renderTable())
 	}
 
 	sections = append(sections, m.renderStatusBar())
@@ -132,18 +134,24 @@ func (m Model) isSearchBarAt(y int) bool {
 
 func (m Model) renderSearchBar() string {
 	style := SearchBarStyle
-	if m.state == stateSearching {
+	if m.state == stateSearching || m.state == stateExporting {
 		style = SearchBarActiveStyle
 	}
 
 	var content string
-	if m.state == stateSearching {
+	if m.state == stateExporting {
+		content = m.exportInput.View() + lipgloss.NewStyle().Foreground(Subtle).Render(
+			fmt.Sprintf("  %d entries · .csv .ndjson .html .md · enter to save, esc to cancel", len(m.exportSelection())))
+	} else if m.state == stateSearching {
 		content = m.searchInput.View()
 	} else if m.searchText != "" {
 		content = SearchLabelStyle.Render("/ ") +
 			lipgloss.NewStyle().Foreground(lipgloss.Color("#FF79C6")).Render(m.searchText)
+		if m.searchEntries != nil {
+			content += lipgloss.NewStyle().Foreground(Subtle).Render("  (all history)")
+		}
 	} else {
-		content = lipgloss.NewStyle().Foreground(Subtle).Render("/ search with regex...")
+		content = lipgloss.NewStyle().Foreground(Subtle).Render("/ filter as you type, ctrl+f to search all history...")
 	}
 
 	w := m.width - 4
@@ -157,10 +165,17 @@ func (m Model) renderStatusBar() string {
 	dot := lipgloss.NewStyle().Foreground(Muted).Render(" · ")
 	var parts []string
 
-	if len(m.filteredEntries) > 0 {
+	if m.showStats {
+		parts = append(parts, lipgloss.NewStyle().Foreground(Accent).Bold(true).Render("stats"))
+	} else if m.rowCount() > 0 {
 		cursor := m.table.Cursor() + 1
 		parts = append(parts, lipgloss.NewStyle().Foreground(Accent).Bold(true).Render(
-			fmt.Sprintf("%d/%d", cursor, len(m.filteredEntries))))
+			fmt.Sprintf("%d/%d", cursor, m.rowCount())))
+	}
+
+	if m.groupBy != "" {
+		parts = append(parts, lipgloss.NewStyle().Foreground(Subtle).Render(
+			fmt.Sprintf("%d %ss", len(m.groups), m.groupBy)))
 	}
 
 	entryText := fmt.Sprintf("%d entries", len(m.filteredEntries))
@@ -187,8 +202,19 @@ func (m Model) renderStatusBar() string {
 }
 
 func (m Model) overlayDialog(bg string) string {
-	title := lipgloss.NewStyle().Bold(true).Foreground(Danger).
-		Render(fmt.Sprintf("Delete %d entries?", len(m.selected)))
+	question := fmt.Sprintf("Delete %d entries?", len(m.selected))
+	if n := len(m.deleteHosts); n > 0 {
+		what := m.deleteHosts[0]
+		if n > 1 {
+			what = fmt.Sprintf("%d hosts", n)
+		}
+		scope := "every browser"
+		if name := m.browserNames[m.activeBrowser]; name != "all" {
+			scope = name
+		}
+		question = fmt.Sprintf("Delete all history of %s in %s?", what, scope)
+	}
+	title := lipgloss.NewStyle().Bold(true).Foreground(Danger).Render(question)
 
 	keyStyle := lipgloss.NewStyle().
 		Bold(true).